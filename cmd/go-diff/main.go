@@ -0,0 +1,395 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+// Command go-diff diffs, patches and fuzzy matches texts with the diffmatchpatch package.
+//
+// Usage:
+//
+//	go-diff diff [flags] FILE1 FILE2
+//	go-diff patch make [flags] FILE1 FILE2
+//	go-diff patch apply [flags] PATCHFILE [FILE]
+//	go-diff match [flags] PATTERN [FILE]
+//
+// A FILE of "-" or a missing FILE is read from standard input.
+//
+// The exit status of diff is 0 if the inputs are the same and 1 if they differ. The exit status of patch apply is 1 if a patch could not be applied, and the one of match is 1 if there is no match. Any other error results in an exit status of 2.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+const usage = `usage:
+  go-diff diff [flags] FILE1 FILE2
+  go-diff patch make [flags] FILE1 FILE2
+  go-diff patch apply [flags] PATCHFILE [FILE]
+  go-diff match [flags] PATTERN [FILE]
+
+A FILE of "-" or a missing FILE is read from standard input.
+Run "go-diff COMMAND -h" for the flags of a command.
+`
+
+// Exit statuses.
+const (
+	exitOK = 0
+	// The inputs differ, a patch failed to apply or there is no match.
+	exitFailed = 1
+	// Invalid arguments or inputs.
+	exitTrouble = 2
+)
+
+// errUsage is returned for invalid flags, after they have been reported along with the usage of the command.
+var errUsage = errors.New("Invalid usage")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command given by args and returns its exit status.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	var err error
+	status := exitOK
+
+	switch {
+	case len(args) == 0:
+		_, _ = io.WriteString(stderr, usage)
+		return exitTrouble
+	case args[0] == "diff":
+		status, err = runDiff(args[1:], stdin, stdout, stderr)
+	case args[0] == "patch" && len(args) > 1 && args[1] == "make":
+		status, err = runPatchMake(args[2:], stdin, stdout, stderr)
+	case args[0] == "patch" && len(args) > 1 && args[1] == "apply":
+		status, err = runPatchApply(args[2:], stdin, stdout, stderr)
+	case args[0] == "match":
+		status, err = runMatch(args[1:], stdin, stdout, stderr)
+	case args[0] == "help" || args[0] == "-h" || args[0] == "-help" || args[0] == "--help":
+		_, _ = io.WriteString(stdout, usage)
+		return exitOK
+	default:
+		_, _ = io.WriteString(stderr, usage)
+		return exitTrouble
+	}
+
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	} else if errors.Is(err, errUsage) {
+		// The flag set has already reported the problem.
+		return exitTrouble
+	} else if err != nil {
+		_, _ = fmt.Fprintf(stderr, "go-diff: %v\n", err)
+		return exitTrouble
+	}
+
+	return status
+}
+
+// newFlagSet creates a flag set for a command, which prints its usage along with the flags to stderr.
+func newFlagSet(name, arguments string, stderr io.Writer) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(stderr, "usage: go-diff %s [flags] %s\n", name, arguments)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parseFlags parses the arguments of a command.
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); errors.Is(err, flag.ErrHelp) {
+		return err
+	} else if err != nil {
+		return errUsage
+	}
+	return nil
+}
+
+// addDiffFlags adds the flags which configure diffing to flags.
+func addDiffFlags(flags *flag.FlagSet, dmp *diffmatchpatch.DiffMatchPatch) func() error {
+	flags.DurationVar(&dmp.DiffTimeout, "timeout", dmp.DiffTimeout, "time to spend on refining a diff, 0 for no limit")
+	algorithm := flags.String("algorithm", "bisect", "diff algorithm: bisect, patience or histogram")
+
+	return func() error {
+		switch *algorithm {
+		case "bisect":
+			dmp.DiffAlgorithm = diffmatchpatch.AlgorithmBisect
+		case "patience":
+			dmp.DiffAlgorithm = diffmatchpatch.AlgorithmPatience
+		case "histogram":
+			dmp.DiffAlgorithm = diffmatchpatch.AlgorithmHistogram
+		default:
+			return errors.New("Unknown diff algorithm: " + *algorithm)
+		}
+		return nil
+	}
+}
+
+// addMatchFlags adds the flags which configure fuzzy matching to flags.
+func addMatchFlags(flags *flag.FlagSet, dmp *diffmatchpatch.DiffMatchPatch) {
+	flags.Float64Var(&dmp.MatchThreshold, "threshold", dmp.MatchThreshold, "at what point no match is declared, from 0.0 for perfection to 1.0 for very loose")
+	flags.IntVar(&dmp.MatchDistance, "distance", dmp.MatchDistance, "how far from the expected location to search for a match, in bytes")
+}
+
+// readInput reads the file with the given name, or stdin if the name is "-".
+func readInput(name string, stdin io.Reader) (string, error) {
+	var data []byte
+	var err error
+	if name == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(name)
+	}
+	return string(data), err
+}
+
+// readInputs reads the files with the given names, of which at most one may be stdin.
+func readInputs(names []string, stdin io.Reader) ([]string, error) {
+	texts := make([]string, len(names))
+	stdinRead := false
+	for i, name := range names {
+		if name == "-" {
+			if stdinRead {
+				return nil, errors.New("Standard input can only be read once")
+			}
+			stdinRead = true
+		}
+		text, err := readInput(name, stdin)
+		if err != nil {
+			return nil, err
+		}
+		texts[i] = text
+	}
+	return texts, nil
+}
+
+// inputNames returns the positional arguments of flags, which have to be between min and max many. Missing optional arguments default to stdin.
+func inputNames(flags *flag.FlagSet, min, max int) ([]string, error) {
+	names := flags.Args()
+	if len(names) < min || len(names) > max {
+		flags.Usage()
+		return nil, fmt.Errorf("Expected %d to %d arguments, got %d", min, max, len(names))
+	}
+	for len(names) < max {
+		names = append(names, "-")
+	}
+	return names, nil
+}
+
+// runDiff executes "go-diff diff".
+func runDiff(args []string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	dmp := diffmatchpatch.New()
+
+	flags := newFlagSet("diff", "FILE1 FILE2", stderr)
+	applyDiffFlags := addDiffFlags(flags, dmp)
+	format := flags.String("format", "unified", "output format: text, pretty, html, unified, terminal, side-by-side or delta")
+	granularity := flags.String("granularity", "char", "what to diff unless the format is unified: char, word or line")
+	semantic := flags.Bool("semantic", false, "clean up the diff to be more human readable")
+	flags.IntVar(&dmp.UnifiedContext, "context", dmp.UnifiedContext, "number of unchanged lines around changes in unified, terminal and side-by-side output")
+	theme := flags.String("theme", "auto", "colors of terminal and side-by-side output: auto, none, 16, 256 or truecolor")
+	flags.BoolVar(&dmp.TerminalBackground, "background", dmp.TerminalBackground, "highlight changes in terminal output with background colors")
+	flags.BoolVar(&dmp.TerminalLineNumbers, "line-numbers", dmp.TerminalLineNumbers, "show line numbers in terminal and side-by-side output")
+	flags.BoolVar(&dmp.TerminalWhitespace, "whitespace", dmp.TerminalWhitespace, "show whitespace in terminal and side-by-side output")
+	width := flags.Int("width", 60, "width of the columns of side-by-side output")
+	if err := parseFlags(flags, args); err != nil {
+		return exitTrouble, err
+	} else if err := applyDiffFlags(); err != nil {
+		return exitTrouble, err
+	}
+	names, err := inputNames(flags, 2, 2)
+	if err != nil {
+		return exitTrouble, err
+	}
+	texts, err := readInputs(names, stdin)
+	if err != nil {
+		return exitTrouble, err
+	}
+	text1, text2 := texts[0], texts[1]
+
+	var diffs []diffmatchpatch.Diff
+	switch *granularity {
+	case "char":
+		diffs = dmp.DiffMain(text1, text2, true)
+	case "word":
+		diffs = dmp.DiffMainWords(text1, text2)
+	case "line":
+		runes1, runes2, lines := dmp.DiffLinesToRunes(text1, text2)
+		diffs = dmp.DiffCharsToLines(dmp.DiffMainRunes(runes1, runes2, false), lines)
+	default:
+		return exitTrouble, errors.New("Unknown diff granularity: " + *granularity)
+	}
+	if *semantic {
+		diffs = dmp.DiffCleanupSemantic(diffs)
+	}
+
+	var output string
+	switch *format {
+	case "text":
+		var text strings.Builder
+		for _, aDiff := range diffs {
+			_, _ = fmt.Fprintf(&text, "%s\t%q\n", aDiff.Type, aDiff.Text)
+		}
+		output = text.String()
+	case "pretty":
+		output = dmp.DiffPrettyText(diffs) + "\n"
+	case "html":
+		output = dmp.DiffPrettyHtml(diffs) + "\n"
+	case "unified":
+		output = dmp.DiffUnified(text1, text2, names[0], names[1])
+	case "terminal", "side-by-side":
+		if dmp.TerminalTheme, err = terminalTheme(*theme, stdout); err != nil {
+			return exitTrouble, err
+		}
+		if *format == "terminal" {
+			output = dmp.DiffTerminal(diffs)
+		} else {
+			output = dmp.DiffSideBySideTerminal(diffs, *width)
+		}
+	case "delta":
+		output = dmp.DiffToDelta(diffs) + "\n"
+	default:
+		return exitTrouble, errors.New("Unknown diff output format: " + *format)
+	}
+	if _, err := io.WriteString(stdout, output); err != nil {
+		return exitTrouble, err
+	}
+
+	if text1 == text2 {
+		return exitOK, nil
+	}
+	return exitFailed, nil
+}
+
+// terminalTheme returns the terminal theme with the given name, where "auto" picks the richest theme which stdout is able to show.
+func terminalTheme(name string, stdout io.Writer) (diffmatchpatch.TerminalTheme, error) {
+	switch name {
+	case "auto":
+		return diffmatchpatch.TerminalThemeFor(stdout), nil
+	case "none":
+		return diffmatchpatch.TerminalThemeNone, nil
+	case "16":
+		return diffmatchpatch.TerminalTheme16, nil
+	case "256":
+		return diffmatchpatch.TerminalTheme256, nil
+	case "truecolor":
+		return diffmatchpatch.TerminalThemeTrueColor, nil
+	}
+	return diffmatchpatch.TerminalThemeNone, errors.New("Unknown terminal theme: " + name)
+}
+
+// runPatchMake executes "go-diff patch make".
+func runPatchMake(args []string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	dmp := diffmatchpatch.New()
+
+	flags := newFlagSet("patch make", "FILE1 FILE2", stderr)
+	applyDiffFlags := addDiffFlags(flags, dmp)
+	flags.IntVar(&dmp.PatchMargin, "margin", dmp.PatchMargin, "number of bytes of context around each patch")
+	if err := parseFlags(flags, args); err != nil {
+		return exitTrouble, err
+	} else if err := applyDiffFlags(); err != nil {
+		return exitTrouble, err
+	}
+	names, err := inputNames(flags, 2, 2)
+	if err != nil {
+		return exitTrouble, err
+	}
+	texts, err := readInputs(names, stdin)
+	if err != nil {
+		return exitTrouble, err
+	}
+
+	patches := dmp.PatchMake(texts[0], texts[1])
+	if _, err := io.WriteString(stdout, dmp.PatchToText(patches)); err != nil {
+		return exitTrouble, err
+	}
+
+	return exitOK, nil
+}
+
+// runPatchApply executes "go-diff patch apply" and reports every patch which failed to apply to stderr.
+func runPatchApply(args []string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	dmp := diffmatchpatch.New()
+
+	flags := newFlagSet("patch apply", "PATCHFILE [FILE]", stderr)
+	applyDiffFlags := addDiffFlags(flags, dmp)
+	addMatchFlags(flags, dmp)
+	flags.Float64Var(&dmp.PatchDeleteThreshold, "delete-threshold", dmp.PatchDeleteThreshold, "how closely the contents of a large deletion have to match, from 0.0 for perfection to 1.0 for very loose")
+	if err := parseFlags(flags, args); err != nil {
+		return exitTrouble, err
+	} else if err := applyDiffFlags(); err != nil {
+		return exitTrouble, err
+	}
+	names, err := inputNames(flags, 1, 2)
+	if err != nil {
+		return exitTrouble, err
+	}
+	texts, err := readInputs(names, stdin)
+	if err != nil {
+		return exitTrouble, err
+	}
+
+	patches, err := dmp.PatchFromText(texts[0])
+	if err != nil {
+		return exitTrouble, err
+	}
+	text, results := dmp.PatchApplyResults(patches, texts[1])
+	if _, err := io.WriteString(stdout, text); err != nil {
+		return exitTrouble, err
+	}
+
+	// Patches which are too long to match are applied in pieces, report each patch only once.
+	status := exitOK
+	failed := -1
+	for _, result := range results {
+		if result.Applied || result.Patch == failed {
+			continue
+		}
+		failed = result.Patch
+		header := strings.SplitN(patches[result.Patch].String(), "\n", 2)[0]
+		_, _ = fmt.Fprintf(stderr, "go-diff: patch %d of %d failed (%s): %s\n", result.Patch+1, len(patches), result.Failure, header)
+		status = exitFailed
+	}
+
+	return status, nil
+}
+
+// runMatch executes "go-diff match" and prints the location of the best match, or -1 if there is none.
+func runMatch(args []string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	dmp := diffmatchpatch.New()
+
+	flags := newFlagSet("match", "PATTERN [FILE]", stderr)
+	addMatchFlags(flags, dmp)
+	loc := flags.Int("loc", 0, "expected location of the pattern, in bytes")
+	if err := parseFlags(flags, args); err != nil {
+		return exitTrouble, err
+	}
+	names, err := inputNames(flags, 1, 2)
+	if err != nil {
+		return exitTrouble, err
+	}
+	text, err := readInput(names[1], stdin)
+	if err != nil {
+		return exitTrouble, err
+	}
+
+	match := dmp.MatchMain(text, names[0], *loc)
+	if _, err := fmt.Fprintln(stdout, match); err != nil {
+		return exitTrouble, err
+	}
+
+	if match == -1 {
+		return exitFailed, nil
+	}
+	return exitOK, nil
+}
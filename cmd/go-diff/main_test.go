@@ -0,0 +1,99 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Args  []string
+		Stdin string
+
+		ExpectedStatus int
+		ExpectedStdout string
+		ExpectedStderr string
+	}
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	file1 := write("file1.txt", "The quick brown fox\njumps over\nthe lazy dog.\n")
+	file2 := write("file2.txt", "The quick red fox\njumps over\nthe lazy dog.\n")
+	patch := write("fox.patch", "@@ -7,13 +7,11 @@\n ick \n-brown\n+red\n  fox\n")
+	// Too long to match as a whole, hence applied in pieces.
+	split := write("split.patch", "@@ -2,65 +2,8 @@\n AAA \n-0123456789abcdefghijklmnopqrstuvwxyz0123456789ABCDEFGHIJ \n ZZZZ\n")
+	failing := write("failing.patch", "@@ -1,8 +1,8 @@\n The \n-quick\n+slow\n  br\n@@ -40,14 +40,15 @@\n xyzzy \n-plugh\n+plover\n qwerty\n")
+
+	for i, tc := range []TestCase{
+		{"No arguments", nil, "", exitTrouble, "", usage},
+		{"Unknown command", []string{"frob"}, "", exitTrouble, "", usage},
+		{"Help", []string{"help"}, "", exitOK, usage, ""},
+
+		{"Diff unified", []string{"diff", file1, file2}, "", exitFailed, "--- " + file1 + "\n+++ " + file2 + "\n@@ -1,3 +1,3 @@\n-The quick brown fox\n+The quick red fox\n jumps over\n the lazy dog.\n", ""},
+		{"Diff same", []string{"diff", file1, file1}, "", exitOK, "", ""},
+		{"Diff text", []string{"diff", "-format", "text", "-granularity", "word", "-", file2}, "The quick brown fox\n", exitFailed, "Equal\t\"The quick \"\nDelete\t\"brown\"\nInsert\t\"red\"\nEqual\t\" fox\"\nInsert\t\"\\njumps over\\nthe lazy dog.\"\nEqual\t\"\\n\"\n", ""},
+		{"Diff stdin twice", []string{"diff", "-format", "text", "-", "-"}, "", exitTrouble, "", "go-diff: Standard input can only be read once\n"},
+		{"Diff delta", []string{"diff", "-format", "delta", file1, file2}, "", exitFailed, "=10\t-1\t=1\t-3\t+ed\t=30\n", ""},
+		{"Diff delta by words", []string{"diff", "-format", "delta", "-granularity", "word", file1, file2}, "", exitFailed, "=10\t-5\t+red\t=30\n", ""},
+		{"Diff delta by lines", []string{"diff", "-format", "delta", "-granularity", "line", file1, file2}, "", exitFailed, "-20\t+The quick red fox%0A\t=25\n", ""},
+		{"Diff html", []string{"diff", "-format", "html", "-granularity", "word", file1, file2}, "", exitFailed, "<span>The quick </span><del style=\"background:#ffe6e6;\">brown</del><ins style=\"background:#e6ffe6;\">red</ins><span> fox&para;<br>jumps over&para;<br>the lazy dog.&para;<br></span>\n", ""},
+		{"Diff pretty", []string{"diff", "-format", "pretty", "-granularity", "word", file1, file2}, "", exitFailed, "The quick \x1b[31mbrown\x1b[0m\x1b[32mred\x1b[0m fox\njumps over\nthe lazy dog.\n\n", ""},
+		{"Diff terminal", []string{"diff", "-format", "terminal", file1, file2}, "", exitFailed, "1   │ -The quick brown fox\n  1 │ +The quick red fox\n2 2 │  jumps over\n3 3 │  the lazy dog.\n", ""},
+		{"Diff terminal with colors", []string{"diff", "-format", "terminal", "-theme", "16", "-line-numbers=false", "-context", "0", file1, file2}, "", exitFailed, "\x1b[31m-\x1b[0m\x1b[31mThe quick \x1b[0m\x1b[7;31mbrown\x1b[0m\x1b[31m fox\x1b[0m\n\x1b[32m+\x1b[0m\x1b[32mThe quick \x1b[0m\x1b[7;32mred\x1b[0m\x1b[32m fox\x1b[0m\n\x1b[36m...\x1b[0m\n", ""},
+		{"Diff side by side", []string{"diff", "-format", "side-by-side", "-width", "12", file1, file2}, "", exitFailed, "1 The quick    | 1 The quick\n  brown fox        red fox\n2 jumps over     2 jumps over\n3 the lazy       3 the lazy\n  dog.             dog.\n", ""},
+		{"Diff unknown theme", []string{"diff", "-format", "terminal", "-theme", "sepia", file1, file2}, "", exitTrouble, "", "go-diff: Unknown terminal theme: sepia\n"},
+		{"Diff unknown format", []string{"diff", "-format", "xml", file1, file2}, "", exitTrouble, "", "go-diff: Unknown diff output format: xml\n"},
+		{"Diff unknown algorithm", []string{"diff", "-algorithm", "magic", file1, file2}, "", exitTrouble, "", "go-diff: Unknown diff algorithm: magic\n"},
+		{"Diff missing file", []string{"diff", file1}, "", exitTrouble, "", "usage: go-diff diff [flags] FILE1 FILE2\n"},
+		{"Diff nonexistent file", []string{"diff", file1, filepath.Join(dir, "nonexistent")}, "", exitTrouble, "", "no such file or directory"},
+
+		{"Patch make", []string{"patch", "make", file1, file2}, "", exitOK, "@@ -7,13 +7,11 @@\n ick \n-brown\n+red\n  fox\n", ""},
+		{"Patch apply", []string{"patch", "apply", patch, file1}, "", exitOK, "The quick red fox\njumps over\nthe lazy dog.\n", ""},
+		{"Patch apply from stdin", []string{"patch", "apply", patch}, "A quick brown fox.", exitOK, "A quick red fox.", ""},
+		{"Patch apply with failed hunk", []string{"patch", "apply", failing, file1}, "", exitFailed, "The slow brown fox\njumps over\nthe lazy dog.\n", "go-diff: patch 2 of 2 failed (NoMatch): @@ -40,14 +40,15 @@\n"},
+		{"Patch apply with failed split patch", []string{"patch", "apply", split, file1}, "", exitFailed, "The quick brown fox\njumps over\nthe lazy dog.\n", "go-diff: patch 1 of 1 failed (NoMatch): @@ -2,65 +2,8 @@\n"},
+		{"Patch apply invalid patch", []string{"patch", "apply", file1, file2}, "", exitTrouble, "", "go-diff: Invalid patch string: The quick brown fox\n"},
+
+		{"Match", []string{"match", "lazy", file1}, "", exitOK, "35\n", ""},
+		{"Match fuzzy", []string{"match", "-loc", "30", "lazi", file1}, "", exitOK, "35\n", ""},
+		{"Match strict", []string{"match", "-threshold", "0.1", "lazi", file1}, "", exitFailed, "-1\n", ""},
+		{"Match distance", []string{"match", "-distance", "0", "-loc", "0", "jumps ouer"}, "The quick brown fox\njumps over\n", exitFailed, "-1\n", ""},
+	} {
+		var stdout, stderr bytes.Buffer
+		status := run(tc.Args, strings.NewReader(tc.Stdin), &stdout, &stderr)
+		assert.Equal(t, tc.ExpectedStatus, status, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedStdout, stdout.String(), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		if tc.ExpectedStderr == "" {
+			assert.Equal(t, "", stderr.String(), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		} else {
+			assert.Contains(t, stderr.String(), tc.ExpectedStderr, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+	}
+
+	// A patch which failed in several pieces is reported once.
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, exitFailed, run([]string{"patch", "apply", split, file1}, strings.NewReader(""), &stdout, &stderr))
+	assert.Equal(t, "go-diff: patch 1 of 1 failed (NoMatch): @@ -2,65 +2,8 @@\n", stderr.String())
+}
@@ -26,3 +26,16 @@ func speedtestTexts() (s1 string, s2 string) {
 
 	return string(d1), string(d2)
 }
+
+func multilingualTexts() (s1 string, s2 string) {
+	d1, err := ioutil.ReadFile(testdataPath + "multilingual1.txt")
+	if err != nil {
+		panic(err)
+	}
+	d2, err := ioutil.ReadFile(testdataPath + "multilingual2.txt")
+	if err != nil {
+		panic(err)
+	}
+
+	return string(d1), string(d2)
+}
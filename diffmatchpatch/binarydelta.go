@@ -0,0 +1,299 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+)
+
+// The header byte of a binary delta holds the version of the format in its upper four bits and flags in the lower ones.
+const (
+	binaryDeltaVersion  = 1 << 4
+	binaryDeltaChecksum = 1 << 0
+)
+
+// Operations in the lowest two bits of the tags of a binary delta, the other bits hold the length of the operation.
+const (
+	binaryDeltaEnd = iota
+	binaryDeltaEqual
+	binaryDeltaDelete
+	binaryDeltaInsert
+)
+
+// DiffToBinaryDelta crushes the diff into a compact binary delta which describes the operations required to transform text1 into text2, along with CRC-32 checksums of both texts if checksum is set.
+// The delta starts with a header byte, which is 0x10, or 0x11 for a delta with checksums. Every operation is a tag, the unsigned varint of length<<2|op, where op is 1 for an equality, 2 for a deletion and 3 for an insertion, and lengths count bytes. Insertions are followed by their raw bytes.
+// A tag of 0 ends the delta, followed by the big-endian IEEE CRC-32 checksums of text1 and text2 if the header says so.
+// Unlike DiffToDelta, the delta holds the raw bytes of the diffs of DiffMainBytes as well.
+func (dmp *DiffMatchPatch) DiffToBinaryDelta(diffs []Diff, checksum bool) []byte {
+	var buff bytes.Buffer
+	encoder := NewBinaryDeltaEncoder(&buff, checksum)
+	for _, aDiff := range diffs {
+		_ = encoder.Encode(aDiff)
+	}
+	_ = encoder.Close()
+	return buff.Bytes()
+}
+
+// DiffFromBinaryDelta given the original text1, and a binary delta created by DiffToBinaryDelta which describes the operations required to transform text1 into text2, computes the full diff.
+// The delta has to span text1 exactly, and to match its checksums if it carries any.
+func (dmp *DiffMatchPatch) DiffFromBinaryDelta(text1 string, delta []byte) ([]Diff, error) {
+	reader := bytes.NewReader(delta)
+	decoder := NewBinaryDeltaDecoder(reader, text1)
+	var diffs []Diff
+	for {
+		aDiff, err := decoder.Decode()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, aDiff)
+	}
+	if reader.Len() > 0 {
+		return nil, fmt.Errorf("Binary delta has %v bytes of trailing data", reader.Len())
+	}
+
+	return diffs, nil
+}
+
+// BinaryDeltaEncoder writes a binary delta as described by DiffToBinaryDelta one diff at a time.
+type BinaryDeltaEncoder struct {
+	w        io.Writer
+	checksum bool
+
+	// Checksums of the source and the target text so far.
+	hash1 hash.Hash32
+	hash2 hash.Hash32
+
+	started bool
+	closed  bool
+	err     error
+}
+
+// NewBinaryDeltaEncoder creates an encoder which writes a binary delta to w, along with checksums of the source and the target text if checksum is set.
+func NewBinaryDeltaEncoder(w io.Writer, checksum bool) *BinaryDeltaEncoder {
+	return &BinaryDeltaEncoder{
+		w:        w,
+		checksum: checksum,
+		hash1:    crc32.NewIEEE(),
+		hash2:    crc32.NewIEEE(),
+	}
+}
+
+// Encode writes the operation of a diff to the delta.
+func (e *BinaryDeltaEncoder) Encode(aDiff Diff) error {
+	if e.closed && e.err == nil {
+		e.err = errors.New("Binary delta encoder is closed")
+	}
+	if e.err != nil {
+		return e.err
+	}
+
+	var op uint64
+	switch aDiff.Type {
+	case DiffEqual:
+		op = binaryDeltaEqual
+	case DiffDelete:
+		op = binaryDeltaDelete
+	case DiffInsert:
+		op = binaryDeltaInsert
+	default:
+		return errors.New("Invalid diff operation: " + aDiff.Type.String())
+	}
+
+	e.writeHeader()
+	e.writeTag(uint64(len(aDiff.Text))<<2 | op)
+	if aDiff.Type == DiffInsert {
+		e.write([]byte(aDiff.Text))
+	}
+
+	if e.checksum {
+		if aDiff.Type != DiffInsert {
+			_, _ = io.WriteString(e.hash1, aDiff.Text)
+		}
+		if aDiff.Type != DiffDelete {
+			_, _ = io.WriteString(e.hash2, aDiff.Text)
+		}
+	}
+
+	return e.err
+}
+
+// Close ends the delta and writes the checksums, if any. It does not close the underlying writer.
+func (e *BinaryDeltaEncoder) Close() error {
+	if e.closed || e.err != nil {
+		return e.err
+	}
+	e.closed = true
+
+	e.writeHeader()
+	e.writeTag(binaryDeltaEnd)
+	if e.checksum {
+		e.write(e.hash1.Sum(nil))
+		e.write(e.hash2.Sum(nil))
+	}
+
+	return e.err
+}
+
+// writeHeader writes the header byte unless it has been written already.
+func (e *BinaryDeltaEncoder) writeHeader() {
+	if e.started {
+		return
+	}
+	e.started = true
+
+	header := byte(binaryDeltaVersion)
+	if e.checksum {
+		header |= binaryDeltaChecksum
+	}
+	e.write([]byte{header})
+}
+
+// writeTag writes a tag as an unsigned varint.
+func (e *BinaryDeltaEncoder) writeTag(tag uint64) {
+	var buff [binary.MaxVarintLen64]byte
+	e.write(buff[:binary.PutUvarint(buff[:], tag)])
+}
+
+// write writes data unless an error occurred before, and remembers the error if the write fails.
+func (e *BinaryDeltaEncoder) write(data []byte) {
+	if e.err == nil {
+		_, e.err = e.w.Write(data)
+	}
+}
+
+// binaryDeltaReader is what BinaryDeltaDecoder reads a binary delta from.
+type binaryDeltaReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// BinaryDeltaDecoder reads a binary delta as described by DiffToBinaryDelta one diff at a time, and validates it against the source text.
+type BinaryDeltaDecoder struct {
+	r     binaryDeltaReader
+	text1 string
+
+	// Position in the source text, and the checksum of the target text so far.
+	pointer int
+	hash2   hash.Hash32
+
+	started  bool
+	checksum bool
+	ended    bool
+	err      error
+}
+
+// NewBinaryDeltaDecoder creates a decoder which reads a binary delta from r and applies it to text1.
+// Readers which are no io.ByteReader are buffered, hence the decoder may read beyond the end of the delta.
+func NewBinaryDeltaDecoder(r io.Reader, text1 string) *BinaryDeltaDecoder {
+	reader, ok := r.(binaryDeltaReader)
+	if !ok {
+		reader = bufio.NewReader(r)
+	}
+	return &BinaryDeltaDecoder{
+		r:     reader,
+		text1: text1,
+		hash2: crc32.NewIEEE(),
+	}
+}
+
+// Decode reads the next diff of the delta.
+// It returns io.EOF at the end of the delta, once the delta has been found to span the source text and to match its checksums if it carries any.
+func (d *BinaryDeltaDecoder) Decode() (Diff, error) {
+	if d.err != nil {
+		return Diff{}, d.err
+	}
+	aDiff, err := d.decode()
+	if err != nil {
+		if err == io.EOF && !d.ended {
+			// The delta ended before its end tag.
+			err = io.ErrUnexpectedEOF
+		}
+		d.err = err
+		return Diff{}, err
+	}
+	return aDiff, nil
+}
+
+// decode reads the next diff, returning io.EOF only if the reader ends early.
+func (d *BinaryDeltaDecoder) decode() (Diff, error) {
+	if !d.started {
+		header, err := d.r.ReadByte()
+		if err != nil {
+			return Diff{}, err
+		} else if header&0xf0 != binaryDeltaVersion {
+			return Diff{}, fmt.Errorf("Unsupported binary delta version: %v", header>>4)
+		} else if header&0x0f&^binaryDeltaChecksum != 0 {
+			return Diff{}, fmt.Errorf("Unknown binary delta flags: %#x", header&0x0f)
+		}
+		d.started = true
+		d.checksum = header&binaryDeltaChecksum != 0
+	}
+
+	tag, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return Diff{}, err
+	}
+	length := tag >> 2
+
+	switch tag & 3 {
+	case binaryDeltaEqual, binaryDeltaDelete:
+		if length > uint64(len(d.text1)-d.pointer) {
+			return Diff{}, fmt.Errorf("Delta length (%v) is longer than source text length (%v)", uint64(d.pointer)+length, len(d.text1))
+		}
+		text := d.text1[d.pointer : d.pointer+int(length)]
+		d.pointer += int(length)
+
+		if tag&3 == binaryDeltaDelete {
+			return Diff{DiffDelete, text}, nil
+		}
+		_, _ = io.WriteString(d.hash2, text)
+		return Diff{DiffEqual, text}, nil
+	case binaryDeltaInsert:
+		// Copy rather than allocate the whole length up front, which might be bogus.
+		var text bytes.Buffer
+		if _, err := io.CopyN(&text, d.r, int64(length)); err != nil {
+			return Diff{}, err
+		}
+		_, _ = d.hash2.Write(text.Bytes())
+		return Diff{DiffInsert, text.String()}, nil
+	}
+
+	if length != 0 {
+		return Diff{}, fmt.Errorf("Invalid binary delta tag: %v", tag)
+	} else if d.pointer != len(d.text1) {
+		return Diff{}, fmt.Errorf("Delta length (%v) is different from source text length (%v)", d.pointer, len(d.text1))
+	}
+	d.ended = true
+
+	if d.checksum {
+		var checksums [2 * crc32.Size]byte
+		if _, err := io.ReadFull(d.r, checksums[:]); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return Diff{}, err
+		}
+		if binary.BigEndian.Uint32(checksums[:crc32.Size]) != crc32.ChecksumIEEE([]byte(d.text1)) {
+			return Diff{}, errors.New("Source text does not match the checksum of the binary delta")
+		} else if binary.BigEndian.Uint32(checksums[crc32.Size:]) != d.hash2.Sum32() {
+			return Diff{}, errors.New("Target text does not match the checksum of the binary delta")
+		}
+	}
+
+	return Diff{}, io.EOF
+}
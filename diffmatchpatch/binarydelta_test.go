@@ -0,0 +1,220 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// binaryDeltaChecksums returns the checksums which end a binary delta from text1 to text2.
+func binaryDeltaChecksums(text1, text2 string) []byte {
+	checksums := make([]byte, 2*crc32.Size)
+	binary.BigEndian.PutUint32(checksums, crc32.ChecksumIEEE([]byte(text1)))
+	binary.BigEndian.PutUint32(checksums[crc32.Size:], crc32.ChecksumIEEE([]byte(text2)))
+	return checksums
+}
+
+func TestDiffToBinaryDelta(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Diffs []Diff
+
+		Expected []byte
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Empty", nil, []byte{0x10, 0x00}},
+		{"Equality", []Diff{{DiffEqual, "abc"}}, []byte{0x10, 0x0d, 0x00}},
+		{"Changes", []Diff{{DiffEqual, "ab"}, {DiffDelete, "c"}, {DiffInsert, "\u0680"}}, []byte{0x10, 0x09, 0x06, 0x0b, 0xda, 0x80, 0x00}},
+		{"Empty diffs", []Diff{{DiffDelete, ""}, {DiffInsert, ""}}, []byte{0x10, 0x02, 0x03, 0x00}},
+		{"Long equality", []Diff{{DiffEqual, strings.Repeat("a", 100)}}, []byte{0x10, 0x91, 0x03, 0x00}},
+	} {
+		actual := dmp.DiffToBinaryDelta(tc.Diffs, false)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		diffs, err := dmp.DiffFromBinaryDelta(dmp.DiffText1(tc.Diffs), actual)
+		assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Diffs, diffs, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		// With checksums the header has its flag set, and the checksums follow the end tag.
+		expected := append([]byte{0x11}, tc.Expected[1:]...)
+		expected = append(expected, binaryDeltaChecksums(dmp.DiffText1(tc.Diffs), dmp.DiffText2(tc.Diffs))...)
+		actual = dmp.DiffToBinaryDelta(tc.Diffs, true)
+		assert.Equal(t, expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		diffs, err = dmp.DiffFromBinaryDelta(dmp.DiffText1(tc.Diffs), actual)
+		assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Diffs, diffs, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Convert a large diff into a binary delta and back again.
+	text1, text2 := speedtestTexts()
+	diffs := dmp.DiffMain(text1, text2, false)
+	for _, checksum := range []bool{false, true} {
+		delta := dmp.DiffToBinaryDelta(diffs, checksum)
+		actual, err := dmp.DiffFromBinaryDelta(text1, delta)
+		assert.NoError(t, err)
+		assert.Equal(t, diffs, actual)
+	}
+
+	// Raw bytes survive a round trip.
+	data1, data2 := []byte("a\xffb\x00c"), []byte("a\xfeb\x00\xc3")
+	diffs = dmp.DiffMainBytes(data1, data2, false)
+	actual, err := dmp.DiffFromBinaryDelta(string(data1), dmp.DiffToBinaryDelta(diffs, true))
+	assert.NoError(t, err)
+	assert.Equal(t, diffs, actual)
+	assert.Equal(t, string(data2), dmp.DiffText2(actual))
+
+	// Non-ASCII insertions are not escaped.
+	diffs = []Diff{{DiffEqual, "jumps over the lazy"}, {DiffInsert, strings.Repeat("\u0680\u4e2d\U0001f600", 10)}}
+	assert.True(t, len(dmp.DiffToBinaryDelta(diffs, false)) < len(dmp.DiffToDelta(diffs))/2)
+}
+
+func TestDiffFromBinaryDelta(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Delta []byte
+
+		ExpectedError string
+	}
+
+	dmp := New()
+
+	checksums := binaryDeltaChecksums("abc", "abd")
+
+	for i, tc := range []TestCase{
+		{"Empty", "", nil, "unexpected EOF"},
+		{"Unsupported version", "", []byte{0x20, 0x00}, "Unsupported binary delta version: 2"},
+		{"Unknown flags", "", []byte{0x12, 0x00}, "Unknown binary delta flags: 0x2"},
+		{"Too long", "abc", []byte{0x10, 0x11, 0x00}, "Delta length (4) is longer than source text length (3)"},
+		{"Too long after equality", "abc", []byte{0x10, 0x09, 0x0a, 0x00}, "Delta length (4) is longer than source text length (3)"},
+		{"Too short", "abc", []byte{0x10, 0x09, 0x00}, "Delta length (2) is different from source text length (3)"},
+		{"Invalid tag", "abc", []byte{0x10, 0x04, 0x00}, "Invalid binary delta tag: 4"},
+		{"Missing end", "abc", []byte{0x10, 0x0d}, "unexpected EOF"},
+		{"Truncated tag", "abc", []byte{0x10, 0x8d}, "unexpected EOF"},
+		{"Truncated insertion", "abc", []byte{0x10, 0x0d, 0x0f, 'a', 'b'}, "unexpected EOF"},
+		{"Truncated checksums", "abc", append([]byte{0x11, 0x09, 0x06, 0x07, 'd', 0x00}, checksums[:5]...), "unexpected EOF"},
+		{"Source checksum", "abd", append([]byte{0x11, 0x09, 0x06, 0x07, 'd', 0x00}, checksums...), "Source text does not match the checksum of the binary delta"},
+		{"Target checksum", "abc", append([]byte{0x11, 0x09, 0x06, 0x07, 'e', 0x00}, checksums...), "Target text does not match the checksum of the binary delta"},
+		{"Trailing data", "abc", []byte{0x10, 0x0d, 0x00, 0x00}, "Binary delta has 1 bytes of trailing data"},
+	} {
+		actual, err := dmp.DiffFromBinaryDelta(tc.Text1, tc.Delta)
+		assert.EqualError(t, err, tc.ExpectedError, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Nil(t, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	actual, err := dmp.DiffFromBinaryDelta("abc", append([]byte{0x11, 0x09, 0x06, 0x07, 'd', 0x00}, checksums...))
+	assert.NoError(t, err)
+	assert.Equal(t, []Diff{{DiffEqual, "ab"}, {DiffDelete, "c"}, {DiffInsert, "d"}}, actual)
+}
+
+// binaryDeltaFailingWriter fails every write after the given number of bytes.
+type binaryDeltaFailingWriter struct {
+	n int
+}
+
+func (w *binaryDeltaFailingWriter) Write(data []byte) (int, error) {
+	if len(data) > w.n {
+		return 0, errors.New("Write failed")
+	}
+	w.n -= len(data)
+	return len(data), nil
+}
+
+func TestBinaryDeltaEncoder(t *testing.T) {
+	var buff bytes.Buffer
+	encoder := NewBinaryDeltaEncoder(&buff, false)
+	assert.NoError(t, encoder.Encode(Diff{DiffEqual, "ab"}))
+	assert.EqualError(t, encoder.Encode(Diff{Operation(2), "c"}), "Invalid diff operation: Operation(2)")
+	assert.NoError(t, encoder.Encode(Diff{DiffInsert, "c"}))
+	assert.NoError(t, encoder.Close())
+	assert.NoError(t, encoder.Close())
+	assert.EqualError(t, encoder.Encode(Diff{DiffInsert, "d"}), "Binary delta encoder is closed")
+	assert.Equal(t, []byte{0x10, 0x09, 0x07, 'c', 0x00}, buff.Bytes())
+
+	// Write errors stick.
+	encoder = NewBinaryDeltaEncoder(&binaryDeltaFailingWriter{2}, true)
+	assert.NoError(t, encoder.Encode(Diff{DiffEqual, "ab"}))
+	assert.EqualError(t, encoder.Encode(Diff{DiffInsert, "c"}), "Write failed")
+	assert.EqualError(t, encoder.Encode(Diff{DiffEqual, "d"}), "Write failed")
+	assert.EqualError(t, encoder.Close(), "Write failed")
+}
+
+func TestBinaryDeltaDecoder(t *testing.T) {
+	dmp := New()
+	text1, text2 := speedtestTexts()
+	diffs := dmp.DiffMain(text1, text2, false)
+	delta := dmp.DiffToBinaryDelta(diffs, true)
+
+	// Decode from a reader which is no io.ByteReader and returns a byte at a time.
+	decoder := NewBinaryDeltaDecoder(iotest.OneByteReader(bytes.NewReader(delta)), text1)
+	var actual []Diff
+	for {
+		aDiff, err := decoder.Decode()
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		actual = append(actual, aDiff)
+	}
+	assert.Equal(t, diffs, actual)
+	_, err := decoder.Decode()
+	assert.Equal(t, io.EOF, err)
+
+	// Errors stick.
+	decoder = NewBinaryDeltaDecoder(bytes.NewReader([]byte{0x10, 0x09, 0x0d, 0x00}), "abc")
+	aDiff, err := decoder.Decode()
+	assert.NoError(t, err)
+	assert.Equal(t, Diff{DiffEqual, "ab"}, aDiff)
+	_, err = decoder.Decode()
+	assert.EqualError(t, err, "Delta length (5) is longer than source text length (3)")
+	_, err = decoder.Decode()
+	assert.EqualError(t, err, "Delta length (5) is longer than source text length (3)")
+}
+
+func BenchmarkDiffToBinaryDelta(b *testing.B) {
+	dmp := New()
+	text1, text2 := speedtestTexts()
+	diffs := dmp.DiffMain(text1, text2, false)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		dmp.DiffToBinaryDelta(diffs, true)
+	}
+}
+
+func BenchmarkDiffFromBinaryDelta(b *testing.B) {
+	dmp := New()
+	text1, text2 := speedtestTexts()
+	delta := dmp.DiffToBinaryDelta(dmp.DiffMain(text1, text2, false), true)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, _ = dmp.DiffFromBinaryDelta(text1, delta)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The byte-slice functions below diff arbitrary bytes losslessly, including invalid UTF-8.
+// Internally every byte is mapped to the rune with the same value, so that the rune-based algorithms and cleanups can be used unchanged. The Text of every Diff they return holds the raw bytes, and all lengths and locations count bytes.
+
+// bytesToRunes maps every byte to the rune with the same value.
+func bytesToRunes(data []byte) []rune {
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return runes
+}
+
+// diffsRunesToBytes rehydrates the text in a diff from runes which each stand for one byte to the raw bytes.
+func diffsRunesToBytes(diffs []Diff) []Diff {
+	for i, aDiff := range diffs {
+		var data []byte
+		for _, r := range aDiff.Text {
+			data = append(data, byte(r))
+		}
+		diffs[i].Text = string(data)
+	}
+	return diffs
+}
+
+// diffsBytesToRunes turns the raw bytes in a diff into runes which each stand for one byte.
+func diffsBytesToRunes(diffs []Diff) []Diff {
+	for i, aDiff := range diffs {
+		diffs[i].Text = string(bytesToRunes([]byte(aDiff.Text)))
+	}
+	return diffs
+}
+
+// diffCleanupSemanticLosslessBytes is DiffCleanupSemanticLossless for a diff of raw bytes.
+// Every byte is cleaned up as a rune of its own, since DiffCleanupSemanticLossless would take any two invalid UTF-8 sequences for the same rune and count runes as bytes.
+func (dmp *DiffMatchPatch) diffCleanupSemanticLosslessBytes(diffs []Diff) []Diff {
+	return diffsRunesToBytes(dmp.DiffCleanupSemanticLossless(diffsBytesToRunes(diffs)))
+}
+
+// DiffMainBytes finds the differences between two byte slices.
+// Unlike DiffMain no invalid UTF-8 sequence is replaced, the Text of every Diff holds the raw bytes so that DiffText1 and DiffText2 reconstruct the inputs exactly.
+// The cleanup functions, which assume UTF-8 text, must not be applied to the result.
+func (dmp *DiffMatchPatch) DiffMainBytes(data1, data2 []byte, checklines bool) []Diff {
+	diffs := dmp.DiffMainRunes(bytesToRunes(data1), bytesToRunes(data2), checklines)
+
+	return diffsRunesToBytes(diffs)
+}
+
+// diffMainBytes finds the differences between two texts byte by byte and gives up refining them once ctx is done, in which case ctx.Err() is returned along with them.
+func (dmp *DiffMatchPatch) diffMainBytes(ctx context.Context, text1, text2 string) ([]Diff, error) {
+	diffs, err := dmp.DiffMainRunesContext(ctx, bytesToRunes([]byte(text1)), bytesToRunes([]byte(text2)), false)
+
+	return diffsRunesToBytes(diffs), err
+}
+
+// DiffToDeltaBytes crushes the diff into an encoded string which describes the operations required to transform data1 into data2.
+// Operations are tab-separated. Equalities and deletions are the number of bytes they span, insertions are their bytes encoded with standard base64.
+// E.g. "=3\t-2\t+aW5n" means keep 3 bytes, delete 2 bytes, insert "ing".
+func (dmp *DiffMatchPatch) DiffToDeltaBytes(diffs []Diff) string {
+	var text bytes.Buffer
+	for i, aDiff := range diffs {
+		if i > 0 {
+			_, _ = text.WriteString("\t")
+		}
+		switch aDiff.Type {
+		case DiffInsert:
+			_, _ = text.WriteString("+")
+			_, _ = text.WriteString(base64.StdEncoding.EncodeToString([]byte(aDiff.Text)))
+		case DiffDelete:
+			_, _ = text.WriteString("-")
+			_, _ = text.WriteString(strconv.Itoa(len(aDiff.Text)))
+		case DiffEqual:
+			_, _ = text.WriteString("=")
+			_, _ = text.WriteString(strconv.Itoa(len(aDiff.Text)))
+		}
+	}
+	return text.String()
+}
+
+// DiffFromDeltaBytes given the original data1, and a delta created by DiffToDeltaBytes which describes the operations required to transform data1 into data2, computes the full diff.
+func (dmp *DiffMatchPatch) DiffFromDeltaBytes(data1 []byte, delta string) (diffs []Diff, err error) {
+	i := 0
+
+	for _, token := range strings.Split(delta, "\t") {
+		if len(token) == 0 {
+			// Blank tokens are ok (from a trailing \t).
+			continue
+		}
+
+		// Each token begins with a one character parameter which specifies the operation of this token (delete, insert, equality).
+		param := token[1:]
+
+		switch op := token[0]; op {
+		case '+':
+			data, err := base64.StdEncoding.DecodeString(param)
+			if err != nil {
+				return nil, err
+			}
+
+			diffs = append(diffs, Diff{DiffInsert, string(data)})
+		case '=', '-':
+			n, err := strconv.ParseInt(param, 10, 0)
+			if err != nil {
+				return nil, err
+			} else if n < 0 {
+				return nil, errors.New("Negative number in DiffFromDeltaBytes: " + param)
+			} else if i+int(n) > len(data1) {
+				return nil, fmt.Errorf("Delta length (%v) is longer than source data length (%v)", i+int(n), len(data1))
+			}
+
+			text := string(data1[i : i+int(n)])
+			i += int(n)
+
+			if op == '=' {
+				diffs = append(diffs, Diff{DiffEqual, text})
+			} else {
+				diffs = append(diffs, Diff{DiffDelete, text})
+			}
+		default:
+			// Anything else is an error.
+			return nil, errors.New("Invalid diff operation in DiffFromDeltaBytes: " + string(token[0]))
+		}
+	}
+
+	if i != len(data1) {
+		return nil, fmt.Errorf("Delta length (%v) is different from source data length (%v)", i, len(data1))
+	}
+
+	return diffs, nil
+}
+
+// PatchMakeBytes computes a list of patches to turn data1 into data2.
+// The patches hold raw bytes and count bytes, PatchToText and PatchFromText round-trip them losslessly.
+func (dmp *DiffMatchPatch) PatchMakeBytes(data1, data2 []byte) []Patch {
+	diffs := dmp.DiffMainRunes(bytesToRunes(data1), bytesToRunes(data2), true)
+	if len(diffs) > 2 {
+		diffs = dmp.DiffCleanupSemantic(diffs)
+		diffs = dmp.DiffCleanupEfficiency(diffs)
+	}
+
+	return dmp.patchMake2(string(data1), diffsRunesToBytes(diffs))
+}
+
+// PatchApplyBytes merges a set of patches made by PatchMakeBytes onto data.
+// Returns the patched data, as well as an array of true/false values indicating which patches were applied.
+func (dmp *DiffMatchPatch) PatchApplyBytes(patches []Patch, data []byte) ([]byte, []bool) {
+	text, results, _ := dmp.patchApply(context.Background(), patches, string(data), true, true)
+
+	return []byte(text), patchResultsApplied(results)
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffMainBytes(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Data1 []byte
+		Data2 []byte
+
+		Expected []Diff
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Null case", nil, nil, nil},
+		{"ASCII", []byte("abc"), []byte("abd"), []Diff{{DiffEqual, "ab"}, {DiffDelete, "c"}, {DiffInsert, "d"}}},
+		{"Invalid UTF-8", []byte{0xff, 0xfe, 'a'}, []byte{0xfe, 'a', 0x80}, []Diff{{DiffDelete, "\xff"}, {DiffEqual, "\xfea"}, {DiffInsert, "\x80"}}},
+		{"Split UTF-8 sequence", []byte("\xe2\x82\xac"), []byte("\xe2\x82\xad"), []Diff{{DiffEqual, "\xe2\x82"}, {DiffDelete, "\xac"}, {DiffInsert, "\xad"}}},
+	} {
+		actual := dmp.DiffMainBytes(tc.Data1, tc.Data2, false)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		data1 := make([]byte, random.Intn(300))
+		random.Read(data1)
+		data2 := append([]byte{}, data1...)
+		for j := 0; j < 5 && len(data2) > 0; j++ {
+			data2[random.Intn(len(data2))] = byte(random.Intn(256))
+		}
+		data2 = append(data2, byte(random.Intn(256)))
+
+		diffs := dmp.DiffMainBytes(data1, data2, true)
+		assert.Equal(t, string(data1), dmp.DiffText1(diffs), fmt.Sprintf("Random case #%d", i))
+		assert.Equal(t, string(data2), dmp.DiffText2(diffs), fmt.Sprintf("Random case #%d", i))
+	}
+}
+
+func TestDiffDeltaBytes(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Data1 []byte
+		Delta string
+
+		ErrorMessagePrefix string
+	}
+
+	dmp := New()
+
+	data1 := []byte("jumps over the lazy\xff")
+	data2 := []byte("jumped over a \tlazy\xfe")
+	diffs := dmp.DiffMainBytes(data1, data2, false)
+
+	delta := dmp.DiffToDeltaBytes(diffs)
+	assert.Equal(t, "=4\t-1\t+ZWQ=\t=6\t-3\t+YQ==\t=1\t+CQ==\t=4\t-1\t+/g==", delta)
+
+	actual, err := dmp.DiffFromDeltaBytes(data1, delta)
+	assert.NoError(t, err)
+	assert.Equal(t, diffs, actual)
+
+	assert.Equal(t, "", dmp.DiffToDeltaBytes(nil))
+
+	actual, err = dmp.DiffFromDeltaBytes(nil, "")
+	assert.NoError(t, err)
+	assert.Nil(t, actual)
+
+	for i, tc := range []TestCase{
+		{"Delta too short", data1, "=4\t-1\t+ZWQ=\t=6\t-3\t+YQ==\t=1\t+CQ==\t=4", "Delta length (19) is different from source data length (20)"},
+		{"Delta too long", data1, "=4\t-1\t+ZWQ=\t=6\t-3\t+YQ==\t=1\t+CQ==\t=4\t-2", "Delta length (21) is longer than source data length (20)"},
+		{"Invalid base64", data1, "+ZWQ", "illegal base64 data"},
+		{"Negative number", data1, "--1", "Negative number in DiffFromDeltaBytes: -1"},
+		{"Invalid operation", data1, "*1", "Invalid diff operation in DiffFromDeltaBytes: *"},
+	} {
+		diffs, err := dmp.DiffFromDeltaBytes(tc.Data1, tc.Delta)
+		assert.Nil(t, diffs, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		if assert.Error(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name)) {
+			assert.Contains(t, err.Error(), tc.ErrorMessagePrefix, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+	}
+}
+
+func TestDiffCleanupSemanticLosslessBytes(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Diffs []Diff
+
+		Expected []Diff
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Different invalid bytes", []Diff{{DiffEqual, "a\x80"}, {DiffInsert, "b\x81"}, {DiffEqual, "c"}}, []Diff{{DiffEqual, "a\x80"}, {DiffInsert, "b\x81"}, {DiffEqual, "c"}}},
+		{"Invalid byte and replacement character", []Diff{{DiffEqual, "a\x80"}, {DiffInsert, "b\ufffd"}, {DiffEqual, "c"}}, []Diff{{DiffEqual, "a\x80"}, {DiffInsert, "b\ufffd"}, {DiffEqual, "c"}}},
+		{"Word boundaries", []Diff{{DiffEqual, "The c\x80"}, {DiffInsert, "at c\x80"}, {DiffEqual, "ame."}}, []Diff{{DiffEqual, "The "}, {DiffInsert, "c\x80at "}, {DiffEqual, "c\x80ame."}}},
+	} {
+		actual := dmp.diffCleanupSemanticLosslessBytes(tc.Diffs)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestPatchBytes(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Data1    []byte
+		Data2    []byte
+		DataBase []byte
+
+		Expected        []byte
+		ExpectedApplies []bool
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Exact match", []byte("\x00\x01\x02\xff\xfe\xfd"), []byte("\x00\x01\x80\xff\xfe\xfd"), []byte("\x00\x01\x02\xff\xfe\xfd"), []byte("\x00\x01\x80\xff\xfe\xfd"), []bool{true}},
+		{"Partial match", []byte("The quick brown fox \xff jumps over the lazy dog."), []byte("That quick brown fox \xfe jumped over a lazy dog."), []byte("The quick red rabbit \xff jumps over the tired tiger."), []byte("That quick red rabbit \xfe jumped over a tired tiger."), []bool{true, true}},
+		{"Imperfect match", []byte("The \x80\x81 fox \xff jumps."), []byte("The \x80\x81 fox \xfe jumps."), []byte("The \x80\x81 fox \x82\xff jumps."), []byte("The \x80\x81 fox \x82\xfe jumps."), []bool{true}},
+	} {
+		patches := dmp.PatchMakeBytes(tc.Data1, tc.Data2)
+
+		actual, actualApplies := dmp.PatchApplyBytes(patches, tc.DataBase)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedApplies, actualApplies, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		// The text representation is lossless.
+		text := dmp.PatchToText(patches)
+		fromText, err := dmp.PatchFromText(text)
+		assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, patches, fromText, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		data1 := make([]byte, random.Intn(300))
+		random.Read(data1)
+		data2 := append([]byte{}, data1...)
+		for j := 0; j < 5 && len(data2) > 0; j++ {
+			data2[random.Intn(len(data2))] = byte(random.Intn(256))
+		}
+
+		actual, actualApplies := dmp.PatchApplyBytes(dmp.PatchMakeBytes(data1, data2), data1)
+		assert.Equal(t, data2, append([]byte{}, actual...), fmt.Sprintf("Random case #%d", i))
+		for _, applied := range actualApplies {
+			assert.True(t, applied, fmt.Sprintf("Random case #%d", i))
+		}
+	}
+}
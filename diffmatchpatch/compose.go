@@ -0,0 +1,379 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// sparseDiff is one operation of a diff of which only some of the text is known.
+// Len is the length of the operation in some unit. Text is empty if the text of the operation is unknown, e.g. the text between two patches.
+type sparseDiff struct {
+	Type Operation
+	Text string
+	Len  int
+}
+
+// known returns if the text of the operation is known.
+func (d sparseDiff) known() bool {
+	return len(d.Text) != 0 || d.Len == 0
+}
+
+// split splits the operation after n units.
+func (d sparseDiff) split(n int, unit Unit) (sparseDiff, sparseDiff) {
+	if !d.known() {
+		return sparseDiff{d.Type, "", n}, sparseDiff{d.Type, "", d.Len - n}
+	}
+	i := unitIndex(d.Text, n, unit)
+	return sparseDiff{d.Type, d.Text[:i], n}, sparseDiff{d.Type, d.Text[i:], d.Len - n}
+}
+
+// DiffCompose combines a diff from text1 to text2 and a diff from text2 to text3 into a diff from text1 to text3.
+// Returns an error if the diffs do not agree on text2.
+func (dmp *DiffMatchPatch) DiffCompose(diffs1, diffs2 []Diff) ([]Diff, error) {
+	composed, err := composeSparse(diffsToSparse(diffs1, UnitByte), diffsToSparse(diffs2, UnitByte), UnitByte, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return sparseToDiffs(dmp.sparseCleanupMerge(composed, UnitByte)), nil
+}
+
+// DiffComposeDelta combines a delta from text1 to text2 and a delta from text2 to text3, as created by DiffToDelta, into a delta from text1 to text3.
+// None of the texts are needed, as the deltas tell the length of every equality and deletion and the text of every insertion. Returns an error if the deltas do not agree on the length of text2.
+func (dmp *DiffMatchPatch) DiffComposeDelta(delta1, delta2 string) (string, error) {
+	sparse1, err := deltaToSparse(delta1)
+	if err != nil {
+		return "", err
+	}
+	sparse2, err := deltaToSparse(delta2)
+	if err != nil {
+		return "", err
+	}
+	composed, err := composeSparse(sparse1, sparse2, UnitRune, false)
+	if err != nil {
+		return "", err
+	}
+
+	tokens := make([]string, 0, len(composed))
+	for _, aDiff := range composed {
+		switch aDiff.Type {
+		case DiffInsert:
+			tokens = append(tokens, dmp.DiffToDelta([]Diff{{DiffInsert, aDiff.Text}}))
+		case DiffDelete:
+			tokens = append(tokens, "-"+strconv.Itoa(aDiff.Len))
+		case DiffEqual:
+			tokens = append(tokens, "="+strconv.Itoa(aDiff.Len))
+		}
+	}
+	return strings.Join(tokens, "\t"), nil
+}
+
+// PatchCompose combines patches from text1 to text2 and patches from text2 to text3 into patches from text1 to text3.
+// The texts are not needed, the patches are combined with what their diffs and context tell about text2. Returns an error if the patches do not agree on text2.
+func (dmp *DiffMatchPatch) PatchCompose(patches1, patches2 []Patch) ([]Patch, error) {
+	sparse1, err := dmp.patchesToSparse(patches1)
+	if err != nil {
+		return nil, err
+	}
+	sparse2, err := dmp.patchesToSparse(patches2)
+	if err != nil {
+		return nil, err
+	}
+	composed, err := composeSparse(sparse1, sparse2, dmp.PatchUnit, true)
+	if err != nil {
+		return nil, err
+	}
+	return dmp.sparseToPatches(dmp.sparseCleanupMerge(composed, dmp.PatchUnit)), nil
+}
+
+// sparseAppend appends an operation to a sparse diff, joining it with the last operation if both are of the same kind.
+// Empty operations are left out.
+func sparseAppend(sparse []sparseDiff, aDiff sparseDiff) []sparseDiff {
+	if aDiff.Len == 0 {
+		return sparse
+	}
+	if n := len(sparse); n != 0 && sparse[n-1].Type == aDiff.Type && sparse[n-1].known() == aDiff.known() {
+		sparse[n-1].Text += aDiff.Text
+		sparse[n-1].Len += aDiff.Len
+		return sparse
+	}
+	return append(sparse, aDiff)
+}
+
+// diffsToSparse converts a diff into a sparse diff which knows all of its text.
+func diffsToSparse(diffs []Diff, unit Unit) []sparseDiff {
+	sparse := make([]sparseDiff, 0, len(diffs))
+	for _, aDiff := range diffs {
+		sparse = append(sparse, sparseDiff{aDiff.Type, aDiff.Text, unitLen(aDiff.Text, unit)})
+	}
+	return sparse
+}
+
+// sparseToDiffs converts a sparse diff which knows all of its text into a diff.
+func sparseToDiffs(sparse []sparseDiff) []Diff {
+	diffs := make([]Diff, 0, len(sparse))
+	for _, aDiff := range sparse {
+		diffs = append(diffs, Diff{aDiff.Type, aDiff.Text})
+	}
+	return diffs
+}
+
+// deltaToSparse parses a delta into a sparse diff counting runes, which knows the text of insertions only.
+func deltaToSparse(delta string) ([]sparseDiff, error) {
+	var sparse []sparseDiff
+	for _, token := range strings.Split(delta, "\t") {
+		if len(token) == 0 {
+			// Blank tokens are ok (from a trailing \t).
+			continue
+		}
+
+		// Each token begins with a one character parameter which specifies the operation of this token (delete, insert, equality).
+		param := token[1:]
+
+		switch op := token[0]; op {
+		case '+':
+			// Decode would change all "+" to " "
+			text, err := url.QueryUnescape(strings.Replace(param, "+", "%2b", -1))
+			if err != nil {
+				return nil, err
+			}
+			if !utf8.ValidString(text) {
+				return nil, fmt.Errorf("invalid UTF-8 token: %q", text)
+			}
+
+			sparse = append(sparse, sparseDiff{DiffInsert, text, utf8.RuneCountInString(text)})
+		case '=', '-':
+			n, err := strconv.ParseInt(param, 10, 0)
+			if err != nil {
+				return nil, err
+			} else if n < 0 {
+				return nil, errors.New("Negative number in DiffComposeDelta: " + param)
+			}
+
+			if op == '=' {
+				sparse = append(sparse, sparseDiff{DiffEqual, "", int(n)})
+			} else {
+				sparse = append(sparse, sparseDiff{DiffDelete, "", int(n)})
+			}
+		default:
+			// Anything else is an error.
+			return nil, errors.New("Invalid diff operation in DiffComposeDelta: " + string(token[0]))
+		}
+	}
+
+	return sparse, nil
+}
+
+// patchesToSparse converts patches into a sparse diff counting PatchUnit, which knows the text of the patches and not the text between them.
+// The sparse diff ends with the last patch, the rest of the text is left alone.
+func (dmp *DiffMatchPatch) patchesToSparse(patches []Patch) ([]sparseDiff, error) {
+	var sparse []sparseDiff
+	// Position in the text the patches are applied to which the sparse diff reached.
+	pointer := 0
+	// How much longer the preceding patches made the text.
+	delta := 0
+	for _, aPatch := range dmp.patchTrimContext(patches) {
+		diffs := diffsToSparse(aPatch.diffs, dmp.PatchUnit)
+		start := aPatch.Start2 - delta
+		if start > pointer {
+			sparse = append(sparse, sparseDiff{DiffEqual, "", start - pointer})
+		} else if start < pointer {
+			// The context of the patch overlaps the preceding patch.
+			if len(diffs) == 0 || diffs[0].Type != DiffEqual || diffs[0].Len < pointer-start {
+				return nil, errors.New("Overlapping patches at: " + strconv.Itoa(aPatch.Start2))
+			}
+			_, diffs[0] = diffs[0].split(pointer-start, dmp.PatchUnit)
+		}
+
+		sparse = append(sparse, diffs...)
+		pointer = max(pointer, start+aPatch.Length1)
+		delta += aPatch.Length2 - aPatch.Length1
+	}
+	return sparse, nil
+}
+
+// composeSparse combines a sparse diff from text1 to text2 and a sparse diff from text2 to text3 into a sparse diff from text1 to text3.
+// If open is true, both sparse diffs leave the text beyond their end alone, otherwise they have to end together.
+func composeSparse(sparse1, sparse2 []sparseDiff, unit Unit, open bool) ([]sparseDiff, error) {
+	var composed []sparseDiff
+	add := func(aDiff sparseDiff) {
+		composed = sparseAppend(composed, aDiff)
+	}
+
+	// The rest of the current operation of either sparse diff.
+	var diff1, diff2 sparseDiff
+	for {
+		for diff1.Len == 0 && len(sparse1) != 0 {
+			diff1, sparse1 = sparse1[0], sparse1[1:]
+		}
+		for diff2.Len == 0 && len(sparse2) != 0 {
+			diff2, sparse2 = sparse2[0], sparse2[1:]
+		}
+		if diff1.Len == 0 && diff2.Len == 0 {
+			break
+		}
+
+		// Deletions of text1 and insertions of text3 do not touch text2.
+		if diff1.Len != 0 && diff1.Type == DiffDelete {
+			add(diff1)
+			diff1 = sparseDiff{}
+			continue
+		}
+		if diff2.Len != 0 && diff2.Type == DiffInsert {
+			add(diff2)
+			diff2 = sparseDiff{}
+			continue
+		}
+
+		// Everything else covers text2, so go over it in pieces which both sparse diffs agree on.
+		if diff1.Len == 0 || diff2.Len == 0 {
+			if !open {
+				return nil, errors.New("Composed diffs disagree on the length of the text in between")
+			}
+			if diff1.Len == 0 {
+				diff1 = sparseDiff{DiffEqual, "", diff2.Len}
+			} else {
+				diff2 = sparseDiff{DiffEqual, "", diff1.Len}
+			}
+		}
+		n := min(diff1.Len, diff2.Len)
+		var piece1, piece2 sparseDiff
+		piece1, diff1 = diff1.split(n, unit)
+		piece2, diff2 = diff2.split(n, unit)
+
+		text := piece1.Text
+		if !piece1.known() {
+			text = piece2.Text
+		} else if piece2.known() && piece1.Text != piece2.Text {
+			return nil, fmt.Errorf("Composed diffs disagree on the text in between: %q != %q", piece1.Text, piece2.Text)
+		}
+
+		switch {
+		case piece1.Type == DiffInsert && piece2.Type == DiffDelete:
+			// Text inserted by the first diff and deleted by the second one is gone.
+		case piece1.Type == DiffInsert:
+			add(sparseDiff{DiffInsert, text, n})
+		case piece2.Type == DiffDelete:
+			add(sparseDiff{DiffDelete, text, n})
+		default:
+			add(sparseDiff{DiffEqual, text, n})
+		}
+	}
+
+	return composed, nil
+}
+
+// sparseCleanupMerge cleans up every run of known operations with DiffCleanupMerge, which also cancels out text which was deleted and inserted again.
+// The equalities at either end of a run are left alone, since they are the context which locates the changes next to unknown text, and changes slid into them would lose it.
+func (dmp *DiffMatchPatch) sparseCleanupMerge(sparse []sparseDiff, unit Unit) []sparseDiff {
+	var cleaned []sparseDiff
+	var diffs []Diff
+	flush := func() {
+		if len(diffs) == 0 {
+			return
+		}
+		start, end := 0, len(diffs)
+		for start < end && diffs[start].Type == DiffEqual {
+			start++
+		}
+		for end > start && diffs[end-1].Type == DiffEqual {
+			end--
+		}
+		// DiffCleanupMerge appends to the slice it is given, which must not overwrite the equalities at the back.
+		run := append(diffs[:start:start], dmp.DiffCleanupMerge(append([]Diff{}, diffs[start:end]...))...)
+		run = append(run, diffs[end:]...)
+		for _, aDiff := range diffsToSparse(run, unit) {
+			cleaned = sparseAppend(cleaned, aDiff)
+		}
+		diffs = nil
+	}
+
+	for _, aDiff := range sparse {
+		if aDiff.known() {
+			diffs = append(diffs, Diff{aDiff.Type, aDiff.Text})
+			continue
+		}
+		flush()
+		cleaned = sparseAppend(cleaned, aDiff)
+	}
+	flush()
+
+	return cleaned
+}
+
+// sparseToPatches converts a sparse diff counting PatchUnit into patches.
+// Every run of changes becomes a patch, along with the known equalities around it as context. Changes are kept in one patch if less than 2*PatchMargin of known equalities lie in between, just like PatchMake does.
+func (dmp *DiffMatchPatch) sparseToPatches(sparse []sparseDiff) []Patch {
+	var patches []Patch
+	patch := Patch{}
+	// Known equality in front of the next change.
+	context := ""
+	// Position in the text the patches are applied to.
+	pointer := 0
+	// How much longer the preceding patches made the text.
+	delta := 0
+
+	flush := func() {
+		if len(patch.diffs) == 0 {
+			return
+		}
+		patches = append(patches, patch)
+		delta += patch.Length2 - patch.Length1
+		patch = Patch{}
+	}
+
+	for i, aDiff := range sparse {
+		switch {
+		case aDiff.Type != DiffEqual:
+			if len(patch.diffs) == 0 {
+				contextLen := unitLen(context, dmp.PatchUnit)
+				patch.Start1 = pointer - contextLen + delta
+				patch.Start2 = patch.Start1
+				if len(context) != 0 {
+					patch.diffs = append(patch.diffs, Diff{DiffEqual, context})
+					patch.Length1 = contextLen
+					patch.Length2 = contextLen
+				}
+			}
+			patch.diffs = append(patch.diffs, Diff{aDiff.Type, aDiff.Text})
+			if aDiff.Type == DiffDelete {
+				patch.Length1 += aDiff.Len
+				pointer += aDiff.Len
+			} else {
+				patch.Length2 += aDiff.Len
+			}
+			context = ""
+		case !aDiff.known():
+			flush()
+			context = ""
+			pointer += aDiff.Len
+		default:
+			if len(patch.diffs) != 0 {
+				patch.diffs = append(patch.diffs, Diff{aDiff.Type, aDiff.Text})
+				patch.Length1 += aDiff.Len
+				patch.Length2 += aDiff.Len
+				if aDiff.Len >= 2*dmp.PatchMargin || i+1 == len(sparse) || !sparse[i+1].known() {
+					// Time for a new patch.
+					flush()
+				}
+			}
+			context = aDiff.Text
+			pointer += aDiff.Len
+		}
+	}
+	flush()
+
+	return patches
+}
@@ -0,0 +1,216 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// composeRevise makes a third revision of the test corpora.
+var composeRevise = strings.NewReplacer("the ", "a ", "über", "ueber", "ψυχο", "ψ").Replace
+
+// composeAlphabet holds few distinct characters of various lengths, so that random texts repeat a lot and changes can be placed in many ways.
+var composeAlphabet = []string{"a", "b", "c", " ", "\n", "\u00e9", "\u65e5", "\U0001f436"}
+
+// composeRandomText makes a random text of up to n characters of composeAlphabet.
+func composeRandomText(random *rand.Rand, n int) string {
+	var text strings.Builder
+	for i := random.Intn(n + 1); i > 0; i-- {
+		_, _ = text.WriteString(composeAlphabet[random.Intn(len(composeAlphabet))])
+	}
+	return text.String()
+}
+
+// composeRandomEdit deletes and inserts a few random runs of characters of text.
+func composeRandomEdit(random *rand.Rand, text string) string {
+	runes := []rune(text)
+	for i := random.Intn(4); i >= 0; i-- {
+		start := random.Intn(len(runes) + 1)
+		end := min(len(runes), start+random.Intn(5))
+		runes = append(runes[:start], append([]rune(composeRandomText(random, 4)), runes[end:]...)...)
+	}
+	return string(runes)
+}
+
+func TestDiffCompose(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Diffs1 []Diff
+		Diffs2 []Diff
+
+		Expected      []Diff
+		ErrorExpected bool
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Null case", []Diff{}, []Diff{}, []Diff{}, false},
+		{"Edits in sequence", []Diff{{DiffEqual, "a"}, {DiffDelete, "b"}, {DiffInsert, "c"}, {DiffEqual, "d"}}, []Diff{{DiffEqual, "ac"}, {DiffInsert, "x"}, {DiffEqual, "d"}}, []Diff{{DiffEqual, "a"}, {DiffDelete, "b"}, {DiffInsert, "cx"}, {DiffEqual, "d"}}, false},
+		{"Insertion deleted again", []Diff{{DiffEqual, "ab"}, {DiffInsert, "123"}}, []Diff{{DiffEqual, "ab"}, {DiffDelete, "123"}}, []Diff{{DiffEqual, "ab"}}, false},
+		{"Deletion inserted again", []Diff{{DiffDelete, "ab"}, {DiffEqual, "c"}}, []Diff{{DiffInsert, "xy"}, {DiffEqual, "c"}}, []Diff{{DiffDelete, "ab"}, {DiffInsert, "xy"}, {DiffEqual, "c"}}, false},
+		{"Unicode", []Diff{{DiffEqual, "ä"}, {DiffInsert, "öü"}}, []Diff{{DiffDelete, "äö"}, {DiffEqual, "ü"}}, []Diff{{DiffDelete, "ä"}, {DiffInsert, "ü"}}, false},
+		{"Texts in between differ", []Diff{{DiffEqual, "abc"}}, []Diff{{DiffEqual, "abd"}}, nil, true},
+		{"Lengths in between differ", []Diff{{DiffEqual, "abc"}}, []Diff{{DiffEqual, "ab"}}, nil, true},
+	} {
+		actual, err := dmp.DiffCompose(tc.Diffs1, tc.Diffs2)
+		if tc.ErrorExpected {
+			assert.Error(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+			continue
+		}
+		assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Compose revisions of the corpora.
+	speedtest1, speedtest2 := speedtestTexts()
+	multilingual1, multilingual2 := multilingualTexts()
+	for _, texts := range [][2]string{{speedtest1, speedtest2}, {multilingual1, multilingual2}} {
+		text3 := composeRevise(texts[1])
+		actual, err := dmp.DiffCompose(dmp.DiffMain(texts[0], texts[1], false), dmp.DiffMain(texts[1], text3, false))
+		assert.NoError(t, err)
+		assert.Equal(t, texts[0], dmp.DiffText1(actual))
+		assert.Equal(t, text3, dmp.DiffText2(actual))
+	}
+}
+
+func TestDiffComposeDelta(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Delta1 string
+		Delta2 string
+
+		Expected      string
+		ErrorExpected bool
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Null case", "", "", "", false},
+		{"Edits in sequence", "=3\t-2\t+XY", "=1\t-2\t+z\t=2", "=1\t-4\t+zXY", false},
+		{"Insertion deleted again", "=2\t+é", "=1\t-2", "=1\t-1", false},
+		{"Escaped insertion", "=1", "+a b%25\t=1", "+a b%25\t=1", false},
+		{"Lengths in between differ", "=3", "=2", "", true},
+		{"Invalid operation", "=1\t*2", "=1", "", true},
+		{"Invalid number", "-x", "", "", true},
+	} {
+		actual, err := dmp.DiffComposeDelta(tc.Delta1, tc.Delta2)
+		if tc.ErrorExpected {
+			assert.Error(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+			continue
+		}
+		assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Compose deltas of the corpora, which count runes rather than bytes.
+	speedtest1, speedtest2 := speedtestTexts()
+	multilingual1, multilingual2 := multilingualTexts()
+	for _, texts := range [][2]string{{speedtest1, speedtest2}, {multilingual1, multilingual2}} {
+		text3 := composeRevise(texts[1])
+		delta1 := dmp.DiffToDelta(dmp.DiffMain(texts[0], texts[1], false))
+		delta2 := dmp.DiffToDelta(dmp.DiffMain(texts[1], text3, false))
+		delta, err := dmp.DiffComposeDelta(delta1, delta2)
+		assert.NoError(t, err)
+
+		actual, err := dmp.DiffFromDelta(texts[0], delta)
+		assert.NoError(t, err)
+		assert.Equal(t, text3, dmp.DiffText2(actual))
+	}
+}
+
+func TestPatchCompose(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+		Text3 string
+
+		Expected string
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Null case", "", "", "", ""},
+		{"Apart", "The quick brown fox jumps over the lazy dog.", "That quick brown fox jumps over the lazy dog.", "That quick brown fox jumps over the lazy cat.", "@@ -1,11 +1,12 @@\n Th\n-e\n+at\n  quick b\n@@ -38,8 +38,8 @@\n azy \n-dog\n+cat\n .\n"},
+		{"Partly undone", "The quick brown fox jumps over the lazy dog.", "That quick brown fox jumps over the lazy dog.", "The quick brown fox jumps over the lazy cat.", "@@ -37,8 +37,8 @@\n azy \n-dog\n+cat\n .\n"},
+		{"Same place", "The quick brown fox jumps over the lazy dog.", "The quick red fox jumps over the lazy dog.", "The quick grey fox jumps over the lazy dog.", "@@ -7,13 +7,12 @@\n ick \n-brown\n+grey\n  fox\n"},
+		{"Undone", "The quick brown fox jumps over the lazy dog.", "The quick red fox jumps over the lazy dog.", "The quick brown fox jumps over the lazy dog.", ""},
+		{"Ambiguous insertion next to unknown text", "aa   ab  abba aaa bb ", "aa   ab  aaabba a ba", "aa    a   ab aaabba a ba", "@@ -2,20 +2,23 @@\n a   \n+ a   \n ab \n- \n+aa\n abba a\n-aa\n  b\n-b \n+a\n"},
+	} {
+		actual, err := dmp.PatchCompose(dmp.PatchMake(tc.Text1, tc.Text2), dmp.PatchMake(tc.Text2, tc.Text3))
+		assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Expected, dmp.PatchToText(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		text, applies := dmp.PatchApply(actual, tc.Text1)
+		assert.Equal(t, tc.Text3, text, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		for _, applied := range applies {
+			assert.True(t, applied, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+	}
+
+	// Patches which do not agree on the text in between.
+	_, err := dmp.PatchCompose(dmp.PatchMake("abc", "abd"), dmp.PatchMake("abc xyz", "abc xyZ"))
+	assert.Error(t, err)
+
+	// Compose revisions of the corpora, in bytes and in runes.
+	speedtest1, speedtest2 := speedtestTexts()
+	multilingual1, multilingual2 := multilingualTexts()
+	for _, unit := range []Unit{UnitByte, UnitRune} {
+		dmp.PatchUnit = unit
+		for _, texts := range [][2]string{{speedtest1, speedtest2}, {speedtest2, speedtest1}, {multilingual1, multilingual2}} {
+			text3 := composeRevise(texts[1])
+			patches, err := dmp.PatchCompose(dmp.PatchMake(texts[0], texts[1]), dmp.PatchMake(texts[1], text3))
+			assert.NoError(t, err, fmt.Sprintf("Unit %s", unit))
+
+			actual, actualResults := dmp.PatchApplyResults(patches, texts[0])
+			assert.Equal(t, text3, actual, fmt.Sprintf("Unit %s", unit))
+			for _, result := range actualResults {
+				assert.True(t, result.Applied, fmt.Sprintf("Unit %s", unit))
+				assert.Equal(t, 0, result.Delta, fmt.Sprintf("Unit %s", unit))
+			}
+
+			// Composing with the inverted patches undoes them.
+			patches, err = dmp.PatchCompose(patches, dmp.PatchInvert(dmp.PatchMake(texts[0], text3)))
+			assert.NoError(t, err, fmt.Sprintf("Unit %s", unit))
+			actual, _ = dmp.PatchApply(patches, texts[0])
+			assert.Equal(t, texts[0], actual, fmt.Sprintf("Unit %s", unit))
+		}
+	}
+
+	// Compose random revisions of random texts, of which changes could be placed in many ways.
+	random := rand.New(rand.NewSource(1))
+	for _, unit := range []Unit{UnitByte, UnitRune} {
+		dmp.PatchUnit = unit
+		for i := 0; i < 3000; i++ {
+			text1 := composeRandomText(random, 80)
+			text2 := composeRandomEdit(random, text1)
+			text3 := composeRandomEdit(random, text2)
+			message := fmt.Sprintf("Unit %s, texts %q, %q, %q", unit, text1, text2, text3)
+
+			patches, err := dmp.PatchCompose(dmp.PatchMake(text1, text2), dmp.PatchMake(text2, text3))
+			if !assert.NoError(t, err, message) {
+				continue
+			}
+			actual, actualApplies := dmp.PatchApply(patches, text1)
+			assert.Equal(t, text3, actual, message)
+			assert.NotContains(t, actualApplies, false, message)
+		}
+	}
+}
@@ -10,6 +10,7 @@ package diffmatchpatch
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"html"
@@ -36,6 +37,20 @@ const (
 	DiffEqual Operation = 0
 )
 
+// Algorithm defines the algorithm used to find the differences once all speedups are exhausted.
+type Algorithm int8
+
+//go:generate stringer -type=Algorithm -trimprefix=Algorithm
+
+const (
+	// AlgorithmBisect is Myers's O(ND) bisection algorithm which produces minimal diffs.
+	AlgorithmBisect Algorithm = iota
+	// AlgorithmPatience anchors the diff on elements which are unique in both texts, which often aligns source code more naturally than a minimal diff.
+	AlgorithmPatience
+	// AlgorithmHistogram anchors the diff on the longest common region of elements which occur rarely, which is fast on texts with many repeated lines.
+	AlgorithmHistogram
+)
+
 // Diff represents one diff operation
 type Diff struct {
 	Type Operation
@@ -88,14 +103,33 @@ func (dmp *DiffMatchPatch) DiffMain(text1, text2 string, checklines bool) []Diff
 // DiffMainRunes finds the differences between two rune sequences.
 // If an invalid UTF-8 sequence is encountered, it will be replaced by the Unicode replacement character.
 func (dmp *DiffMatchPatch) DiffMainRunes(text1, text2 []rune, checklines bool) []Diff {
-	var deadline time.Time
+	diffs, _ := dmp.DiffMainRunesContext(context.Background(), text1, text2, checklines)
+	return diffs
+}
+
+// DiffMainContext finds the differences between two texts and gives up refining them once ctx is done or DiffTimeout is reached.
+// The diff is always complete but may not be minimal if the computation was cut short, in which case ctx.Err() is returned along with it.
+// If an invalid UTF-8 sequence is encountered, it will be replaced by the Unicode replacement character.
+func (dmp *DiffMatchPatch) DiffMainContext(ctx context.Context, text1, text2 string, checklines bool) ([]Diff, error) {
+	return dmp.DiffMainRunesContext(ctx, []rune(text1), []rune(text2), checklines)
+}
+
+// DiffMainRunesContext finds the differences between two rune sequences and gives up refining them once ctx is done or DiffTimeout is reached.
+// The diff is always complete but may not be minimal if the computation was cut short, in which case ctx.Err() is returned along with it.
+// If an invalid UTF-8 sequence is encountered, it will be replaced by the Unicode replacement character.
+func (dmp *DiffMatchPatch) DiffMainRunesContext(ctx context.Context, text1, text2 []rune, checklines bool) ([]Diff, error) {
+	diffCtx := ctx
 	if dmp.DiffTimeout > 0 {
-		deadline = time.Now().Add(dmp.DiffTimeout)
+		var cancel context.CancelFunc
+		diffCtx, cancel = context.WithTimeout(ctx, dmp.DiffTimeout)
+		defer cancel()
 	}
-	return dmp.diffMainRunes(text1, text2, checklines, deadline)
+	diffs := dmp.diffMainRunes(diffCtx, text1, text2, checklines)
+	// Reaching DiffTimeout is not an error.
+	return diffs, ctx.Err()
 }
 
-func (dmp *DiffMatchPatch) diffMainRunes(text1, text2 []rune, checklines bool, deadline time.Time) []Diff {
+func (dmp *DiffMatchPatch) diffMainRunes(ctx context.Context, text1, text2 []rune, checklines bool) []Diff {
 	if runesEqual(text1, text2) {
 		var diffs []Diff
 		if len(text1) > 0 {
@@ -116,7 +150,7 @@ func (dmp *DiffMatchPatch) diffMainRunes(text1, text2 []rune, checklines bool, d
 	text2 = text2[:len(text2)-commonlength]
 
 	// Compute the diff on the middle block.
-	diffs := dmp.diffCompute(text1, text2, checklines, deadline)
+	diffs := dmp.diffCompute(ctx, text1, text2, checklines)
 
 	// Restore the prefix and suffix.
 	if len(commonprefix) != 0 {
@@ -130,7 +164,7 @@ func (dmp *DiffMatchPatch) diffMainRunes(text1, text2 []rune, checklines bool, d
 }
 
 // diffCompute finds the differences between two rune slices.  Assumes that the texts do not have any common prefix or suffix.
-func (dmp *DiffMatchPatch) diffCompute(text1, text2 []rune, checklines bool, deadline time.Time) []Diff {
+func (dmp *DiffMatchPatch) diffCompute(ctx context.Context, text1, text2 []rune, checklines bool) []Diff {
 	diffs := []Diff{}
 	if len(text1) == 0 {
 		// Just add some text (speedup).
@@ -177,25 +211,40 @@ func (dmp *DiffMatchPatch) diffCompute(text1, text2 []rune, checklines bool, dea
 		text2B := hm[3]
 		midCommon := hm[4]
 		// Send both pairs off for separate processing.
-		diffsA := dmp.diffMainRunes(text1A, text2A, checklines, deadline)
-		diffsB := dmp.diffMainRunes(text1B, text2B, checklines, deadline)
+		diffsA := dmp.diffMainRunes(ctx, text1A, text2A, checklines)
+		diffsB := dmp.diffMainRunes(ctx, text1B, text2B, checklines)
 		// Merge the results.
 		diffs := diffsA
 		diffs = append(diffs, Diff{DiffEqual, string(midCommon)})
 		diffs = append(diffs, diffsB...)
 		return diffs
 	} else if checklines && len(text1) > 100 && len(text2) > 100 {
-		return dmp.diffLineMode(text1, text2, deadline)
+		return dmp.diffLineMode(ctx, text1, text2)
 	}
-	return dmp.diffBisect(text1, text2, deadline)
+
+	if ctx.Err() != nil {
+		// Out of time, don't try to find any more commonality.
+		return []Diff{
+			Diff{DiffDelete, string(text1)},
+			Diff{DiffInsert, string(text2)},
+		}
+	}
+
+	switch dmp.DiffAlgorithm {
+	case AlgorithmPatience:
+		return dmp.diffPatience(ctx, text1, text2)
+	case AlgorithmHistogram:
+		return dmp.diffHistogram(ctx, text1, text2)
+	}
+	return dmp.diffBisect(ctx, text1, text2)
 }
 
 // diffLineMode does a quick line-level diff on both []runes, then rediff the parts for greater accuracy. This speedup can produce non-minimal diffs.
-func (dmp *DiffMatchPatch) diffLineMode(text1, text2 []rune, deadline time.Time) []Diff {
+func (dmp *DiffMatchPatch) diffLineMode(ctx context.Context, text1, text2 []rune) []Diff {
 	// Scan the text on a line-by-line basis first.
 	text1, text2, linearray := dmp.diffLinesToRunes(text1, text2)
 
-	diffs := dmp.diffMainRunes(text1, text2, false, deadline)
+	diffs := dmp.diffMainRunes(ctx, text1, text2, false)
 
 	// Convert the diff back to original text.
 	diffs = dmp.DiffCharsToLines(diffs, linearray)
@@ -230,7 +279,7 @@ func (dmp *DiffMatchPatch) diffLineMode(text1, text2 []rune, deadline time.Time)
 					countDelete+countInsert)
 
 				pointer = pointer - countDelete - countInsert
-				a := dmp.diffMainRunes([]rune(textDelete), []rune(textInsert), false, deadline)
+				a := dmp.diffMainRunes(ctx, []rune(textDelete), []rune(textInsert), false)
 				for j := len(a) - 1; j >= 0; j-- {
 					diffs = splice(diffs, pointer, 0, a[j])
 				}
@@ -253,16 +302,35 @@ func (dmp *DiffMatchPatch) diffLineMode(text1, text2 []rune, deadline time.Time)
 // See Myers 1986 paper: An O(ND) Difference Algorithm and Its Variations.
 func (dmp *DiffMatchPatch) DiffBisect(text1, text2 string, deadline time.Time) []Diff {
 	// Unused in this code, but retained for interface compatibility.
-	return dmp.diffBisect([]rune(text1), []rune(text2), deadline)
+	ctx := context.Background()
+	if !deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+	return dmp.diffBisect(ctx, []rune(text1), []rune(text2))
 }
 
 // diffBisect finds the 'middle snake' of a diff, splits the problem in two and returns the recursively constructed diff.
 // See Myers's 1986 paper: An O(ND) Difference Algorithm and Its Variations.
-func (dmp *DiffMatchPatch) diffBisect(runes1, runes2 []rune, deadline time.Time) []Diff {
+func (dmp *DiffMatchPatch) diffBisect(ctx context.Context, runes1, runes2 []rune) []Diff {
+	if x, y, ok := bisectMiddleSnake(ctx, runes1, runes2); ok {
+		return dmp.diffBisectSplit(ctx, runes1, runes2, x, y)
+	}
+	// Diff took too long and hit the deadline or number of diffs equals number of characters, no commonality at all.
+	return []Diff{
+		Diff{DiffDelete, string(runes1)},
+		Diff{DiffInsert, string(runes2)},
+	}
+}
+
+// bisectMiddleSnake finds the 'middle snake' of the edit graph of two slices and returns the point at which the problem can be split in two.
+// Returns false if ctx is done before the snake is found or if the slices have nothing in common.
+func bisectMiddleSnake[T comparable](ctx context.Context, seq1, seq2 []T) (int, int, bool) {
 	// Cache the text lengths to prevent multiple calls.
-	runes1Len, runes2Len := len(runes1), len(runes2)
+	len1, len2 := len(seq1), len(seq2)
 
-	maxD := (runes1Len + runes2Len + 1) / 2
+	maxD := (len1 + len2 + 1) / 2
 	vOffset := maxD
 	vLength := 2 * maxD
 
@@ -275,7 +343,7 @@ func (dmp *DiffMatchPatch) diffBisect(runes1, runes2 []rune, deadline time.Time)
 	v1[vOffset+1] = 0
 	v2[vOffset+1] = 0
 
-	delta := runes1Len - runes2Len
+	delta := len1 - len2
 	// If the total number of characters is odd, then the front path will collide with the reverse path.
 	front := (delta%2 != 0)
 	// Offsets for start and end of k loop. Prevents mapping of space beyond the grid.
@@ -284,8 +352,8 @@ func (dmp *DiffMatchPatch) diffBisect(runes1, runes2 []rune, deadline time.Time)
 	k2start := 0
 	k2end := 0
 	for d := 0; d < maxD; d++ {
-		// Bail out if deadline is reached.
-		if !deadline.IsZero() && d%16 == 0 && time.Now().After(deadline) {
+		// Bail out if the deadline is reached or the diff was cancelled.
+		if d%16 == 0 && ctx.Err() != nil {
 			break
 		}
 
@@ -301,28 +369,28 @@ func (dmp *DiffMatchPatch) diffBisect(runes1, runes2 []rune, deadline time.Time)
 			}
 
 			y1 := x1 - k1
-			for x1 < runes1Len && y1 < runes2Len {
-				if runes1[x1] != runes2[y1] {
+			for x1 < len1 && y1 < len2 {
+				if seq1[x1] != seq2[y1] {
 					break
 				}
 				x1++
 				y1++
 			}
 			v1[k1Offset] = x1
-			if x1 > runes1Len {
+			if x1 > len1 {
 				// Ran off the right of the graph.
 				k1end += 2
-			} else if y1 > runes2Len {
+			} else if y1 > len2 {
 				// Ran off the bottom of the graph.
 				k1start += 2
 			} else if front {
 				k2Offset := vOffset + delta - k1
 				if k2Offset >= 0 && k2Offset < vLength && v2[k2Offset] != -1 {
 					// Mirror x2 onto top-left coordinate system.
-					x2 := runes1Len - v2[k2Offset]
+					x2 := len1 - v2[k2Offset]
 					if x1 >= x2 {
 						// Overlap detected.
-						return dmp.diffBisectSplit(runes1, runes2, x1, y1, deadline)
+						return x1, y1, true
 					}
 				}
 			}
@@ -337,18 +405,18 @@ func (dmp *DiffMatchPatch) diffBisect(runes1, runes2 []rune, deadline time.Time)
 				x2 = v2[k2Offset-1] + 1
 			}
 			var y2 = x2 - k2
-			for x2 < runes1Len && y2 < runes2Len {
-				if runes1[runes1Len-x2-1] != runes2[runes2Len-y2-1] {
+			for x2 < len1 && y2 < len2 {
+				if seq1[len1-x2-1] != seq2[len2-y2-1] {
 					break
 				}
 				x2++
 				y2++
 			}
 			v2[k2Offset] = x2
-			if x2 > runes1Len {
+			if x2 > len1 {
 				// Ran off the left of the graph.
 				k2end += 2
-			} else if y2 > runes2Len {
+			} else if y2 > len2 {
 				// Ran off the top of the graph.
 				k2start += 2
 			} else if !front {
@@ -357,32 +425,27 @@ func (dmp *DiffMatchPatch) diffBisect(runes1, runes2 []rune, deadline time.Time)
 					x1 := v1[k1Offset]
 					y1 := vOffset + x1 - k1Offset
 					// Mirror x2 onto top-left coordinate system.
-					x2 = runes1Len - x2
+					x2 = len1 - x2
 					if x1 >= x2 {
 						// Overlap detected.
-						return dmp.diffBisectSplit(runes1, runes2, x1, y1, deadline)
+						return x1, y1, true
 					}
 				}
 			}
 		}
 	}
-	// Diff took too long and hit the deadline or number of diffs equals number of characters, no commonality at all.
-	return []Diff{
-		Diff{DiffDelete, string(runes1)},
-		Diff{DiffInsert, string(runes2)},
-	}
+	return 0, 0, false
 }
 
-func (dmp *DiffMatchPatch) diffBisectSplit(runes1, runes2 []rune, x, y int,
-	deadline time.Time) []Diff {
+func (dmp *DiffMatchPatch) diffBisectSplit(ctx context.Context, runes1, runes2 []rune, x, y int) []Diff {
 	runes1a := runes1[:x]
 	runes2a := runes2[:y]
 	runes1b := runes1[x:]
 	runes2b := runes2[y:]
 
 	// Compute both diffs serially.
-	diffs := dmp.diffMainRunes(runes1a, runes2a, false, deadline)
-	diffsb := dmp.diffMainRunes(runes1b, runes2b, false, deadline)
+	diffs := dmp.diffMainRunes(ctx, runes1a, runes2a, false)
+	diffsb := dmp.diffMainRunes(ctx, runes1b, runes2b, false)
 
 	return append(diffs, diffsb...)
 }
@@ -556,6 +619,9 @@ func (dmp *DiffMatchPatch) diffHalfMatch(text1, text2 []rune) [][]rune {
 	if dmp.DiffTimeout <= 0 {
 		// Don't risk returning a non-optimal diff if we have unlimited time.
 		return nil
+	} else if dmp.DiffAlgorithm != AlgorithmBisect {
+		// The other algorithms are fast enough on their own and a half-match would override their alignment.
+		return nil
 	}
 
 	var longtext, shorttext []rune
@@ -1123,7 +1189,13 @@ func (dmp *DiffMatchPatch) DiffCleanupMerge(diffs []Diff) []Diff {
 }
 
 // DiffXIndex returns the equivalent location in s2.
+// Locations count bytes or runes, depending on PatchUnit.
 func (dmp *DiffMatchPatch) DiffXIndex(diffs []Diff, loc int) int {
+	return diffXIndex(diffs, loc, dmp.PatchUnit)
+}
+
+// diffXIndex returns the equivalent location in s2, counting in the given unit.
+func diffXIndex(diffs []Diff, loc int, unit Unit) int {
 	chars1 := 0
 	chars2 := 0
 	lastChars1 := 0
@@ -1133,11 +1205,11 @@ func (dmp *DiffMatchPatch) DiffXIndex(diffs []Diff, loc int) int {
 		aDiff := diffs[i]
 		if aDiff.Type != DiffInsert {
 			// Equality or deletion.
-			chars1 += len(aDiff.Text)
+			chars1 += unitLen(aDiff.Text, unit)
 		}
 		if aDiff.Type != DiffDelete {
 			// Equality or insertion.
-			chars2 += len(aDiff.Text)
+			chars2 += unitLen(aDiff.Text, unit)
 		}
 		if chars1 > loc {
 			// Overshot the location.
@@ -1227,6 +1299,29 @@ func (dmp *DiffMatchPatch) DiffText2(diffs []Diff) string {
 	return text.String()
 }
 
+// DiffInvert returns the diff which turns the destination text back into the source text, with insertions and deletions swapped.
+// Deletions still precede insertions between two equalities.
+func (dmp *DiffMatchPatch) DiffInvert(diffs []Diff) []Diff {
+	inverted := make([]Diff, 0, len(diffs))
+	// Index in inverted at which the deletions of the current run of changes end.
+	deletions := 0
+	for _, aDiff := range diffs {
+		switch aDiff.Type {
+		case DiffEqual:
+			inverted = append(inverted, aDiff)
+			deletions = len(inverted)
+		case DiffInsert:
+			inverted = append(inverted, Diff{})
+			copy(inverted[deletions+1:], inverted[deletions:])
+			inverted[deletions] = Diff{DiffDelete, aDiff.Text}
+			deletions++
+		case DiffDelete:
+			inverted = append(inverted, Diff{DiffInsert, aDiff.Text})
+		}
+	}
+	return inverted
+}
+
 // DiffLevenshtein computes the Levenshtein distance that is the number of inserted, deleted or substituted characters.
 func (dmp *DiffMatchPatch) DiffLevenshtein(diffs []Diff) int {
 	levenshtein := 0
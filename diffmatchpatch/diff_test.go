@@ -10,6 +10,7 @@ package diffmatchpatch
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -288,8 +289,8 @@ func TestDiffBisectSplit(t *testing.T) {
 	for _, tc := range []TestCase{
 		{"STUV\x05WX\x05YZ\x05[", "WĺĻļ\x05YZ\x05ĽľĿŀZ"},
 	} {
-		diffs := dmp.diffBisectSplit([]rune(tc.Text1),
-			[]rune(tc.Text2), 7, 6, time.Now().Add(time.Hour))
+		diffs := dmp.diffBisectSplit(context.Background(), []rune(tc.Text1),
+			[]rune(tc.Text2), 7, 6)
 
 		for _, d := range diffs {
 			assert.True(t, utf8.ValidString(d.Text))
@@ -1031,6 +1032,38 @@ func TestDiffText(t *testing.T) {
 	}
 }
 
+func TestDiffInvert(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Diffs []Diff
+
+		Expected []Diff
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Null case", []Diff{}, []Diff{}},
+		{"Equality", []Diff{{DiffEqual, "abc"}}, []Diff{{DiffEqual, "abc"}}},
+		{"Insertion", []Diff{{DiffEqual, "a"}, {DiffInsert, "123"}, {DiffEqual, "b"}}, []Diff{{DiffEqual, "a"}, {DiffDelete, "123"}, {DiffEqual, "b"}}},
+		{"Deletion", []Diff{{DiffDelete, "123"}, {DiffEqual, "ab"}}, []Diff{{DiffInsert, "123"}, {DiffEqual, "ab"}}},
+		{"Substitutions", []Diff{{DiffEqual, "jump"}, {DiffDelete, "s"}, {DiffInsert, "ed"}, {DiffEqual, " over "}, {DiffDelete, "the"}, {DiffInsert, "a"}}, []Diff{{DiffEqual, "jump"}, {DiffDelete, "ed"}, {DiffInsert, "s"}, {DiffEqual, " over "}, {DiffDelete, "a"}, {DiffInsert, "the"}}},
+		{"Interleaved changes", []Diff{{DiffDelete, "a"}, {DiffInsert, "1"}, {DiffDelete, "b"}, {DiffInsert, "2"}}, []Diff{{DiffDelete, "1"}, {DiffDelete, "2"}, {DiffInsert, "a"}, {DiffInsert, "b"}}},
+	} {
+		actual := dmp.DiffInvert(tc.Diffs)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, dmp.DiffText2(tc.Diffs), dmp.DiffText1(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, dmp.DiffText1(tc.Diffs), dmp.DiffText2(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Inverting twice restores a diff made by DiffMain.
+	text1, text2 := speedtestTexts()
+	diffs := dmp.DiffMain(text1, text2, false)
+	assert.Equal(t, diffs, dmp.DiffInvert(dmp.DiffInvert(diffs)))
+	assert.Equal(t, text1, dmp.DiffText2(dmp.DiffInvert(diffs)))
+}
+
 func TestDiffDelta(t *testing.T) {
 	type TestCase struct {
 		Name string
@@ -1126,6 +1159,7 @@ func TestDiffXIndex(t *testing.T) {
 
 		Diffs    []Diff
 		Location int
+		Unit     Unit
 
 		Expected int
 	}
@@ -1133,9 +1167,13 @@ func TestDiffXIndex(t *testing.T) {
 	dmp := New()
 
 	for i, tc := range []TestCase{
-		{"Translation on equality", []Diff{{DiffDelete, "a"}, {DiffInsert, "1234"}, {DiffEqual, "xyz"}}, 2, 5},
-		{"Translation on deletion", []Diff{{DiffEqual, "a"}, {DiffDelete, "1234"}, {DiffEqual, "xyz"}}, 3, 1},
+		{"Translation on equality", []Diff{{DiffDelete, "a"}, {DiffInsert, "1234"}, {DiffEqual, "xyz"}}, 2, UnitByte, 5},
+		{"Translation on deletion", []Diff{{DiffEqual, "a"}, {DiffDelete, "1234"}, {DiffEqual, "xyz"}}, 3, UnitByte, 1},
+		{"Translation on equality in bytes", []Diff{{DiffDelete, "\u00e9"}, {DiffInsert, "\u65e5\u672c"}, {DiffEqual, "xyz"}}, 3, UnitByte, 7},
+		{"Translation on equality in runes", []Diff{{DiffDelete, "\u00e9"}, {DiffInsert, "\u65e5\u672c"}, {DiffEqual, "xyz"}}, 2, UnitRune, 3},
+		{"Translation on deletion in runes", []Diff{{DiffEqual, "\U0001f98a"}, {DiffDelete, "\u65e5\u672c"}, {DiffEqual, "xyz"}}, 2, UnitRune, 1},
 	} {
+		dmp.PatchUnit = tc.Unit
 		actual := dmp.DiffXIndex(tc.Diffs, tc.Location)
 		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
 	}
@@ -1378,6 +1416,53 @@ func TestDiffMainWithTimeout(t *testing.T) {
 	assert.True(t, delta < (dmp.DiffTimeout*100), fmt.Sprintf("%v !< %v", delta, dmp.DiffTimeout*100))
 }
 
+func TestDiffMainContext(t *testing.T) {
+	dmp := New()
+	dmp.DiffTimeout = 0
+
+	a := "`Twas brillig, and the slithy toves\nDid gyre and gimble in the wabe:\nAll mimsy were the borogoves,\nAnd the mome raths outgrabe.\n"
+	b := "I am the very model of a modern major general,\nI've information vegetable, animal, and mineral,\nI know the kings of England, and I quote the fights historical,\nFrom Marathon to Waterloo, in order categorical.\n"
+
+	diffs, err := dmp.DiffMainContext(context.Background(), a, b, false)
+	assert.NoError(t, err)
+	assert.Equal(t, dmp.DiffMain(a, b, false), diffs)
+
+	// A cancelled diff still yields a valid, if not minimal, diff.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	diffs, err = dmp.DiffMainContext(ctx, a, b, true)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, a, dmp.DiffText1(diffs))
+	assert.Equal(t, b, dmp.DiffText2(diffs))
+
+	// Cancellation takes effect while diffing.
+	for x := 0; x < 13; x++ {
+		a = a + a
+		b = b + b
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	startTime := time.Now()
+	diffs, err = dmp.DiffMainContext(ctx, a, b, false)
+	delta := time.Since(startTime)
+
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, delta < 100*100*time.Millisecond, fmt.Sprintf("%v !< %v", delta, 100*100*time.Millisecond))
+	assert.Equal(t, a, dmp.DiffText1(diffs))
+	assert.Equal(t, b, dmp.DiffText2(diffs))
+
+	// Hitting DiffTimeout is not an error.
+	dmp.DiffTimeout = 10 * time.Millisecond
+
+	diffs, err = dmp.DiffMainContext(context.Background(), a, b, false)
+	assert.NoError(t, err)
+	assert.Equal(t, a, dmp.DiffText1(diffs))
+	assert.Equal(t, b, dmp.DiffText2(diffs))
+}
+
 func TestDiffMainWithCheckLines(t *testing.T) {
 	type TestCase struct {
 		Text1 string
@@ -17,18 +17,36 @@ import (
 type DiffMatchPatch struct {
 	// Number of seconds to map a diff before giving up (0 for infinity).
 	DiffTimeout time.Duration
+	// Algorithm used to diff texts which cannot be split up by any speedup.
+	DiffAlgorithm Algorithm
 	// Cost of an empty edit operation in terms of edit characters.
 	DiffEditCost int
+	// Splits texts into the tokens which are compared by DiffMainWords. The tokens of a text have to add up to the text. TokenizeWords is used if nil.
+	DiffTokenizer func(text string) []string
 	// How far to search for a match (0 = exact location, 1000+ = broad match). A match this many characters away from the expected location will add 1.0 to the score (0.0 is a perfect match).
 	MatchDistance int
 	// When deleting a large block of text (over ~64 characters), how close do the contents have to be to match the expected contents. (0.0 = perfection, 1.0 = very loose).  Note that MatchThreshold controls how closely the end points of a delete need to match.
 	PatchDeleteThreshold float64
 	// Chunk size for context length.
 	PatchMargin int
-	// The number of bits in an int.
+	// Unit in which patches, DiffXIndex and matching count positions and lengths, UnitByte or UnitRune. Patches never split a rune, whichever unit is used.
+	PatchUnit Unit
+	// The length of the longest pattern located as a whole when applying patches, longer patches are split up or located by their ends (0 for no limit).
 	MatchMaxBits int
 	// At what point is no match declared (0.0 = perfection, 1.0 = very loose).
 	MatchThreshold float64
+	// Unit of the symbols which fuzzy matching compares, UnitByte, UnitRune or UnitGrapheme. Errors and distances count symbols, whereas locations are counted in PatchUnit.
+	MatchUnit Unit
+	// Number of unchanged lines to show around each change of a unified diff.
+	UnifiedContext int
+	// Escape codes with which DiffTerminal colors diffs.
+	TerminalTheme TerminalTheme
+	// Whether DiffTerminal highlights changes with background rather than foreground colors.
+	TerminalBackground bool
+	// Whether DiffTerminal shows the line numbers of both texts in a gutter.
+	TerminalLineNumbers bool
+	// Whether DiffTerminal shows spaces, tabs, carriage returns and newlines as visible markers.
+	TerminalWhitespace bool
 }
 
 // New creates a new DiffMatchPatch object with default parameters.
@@ -42,5 +60,8 @@ func New() *DiffMatchPatch {
 		PatchDeleteThreshold: 0.5,
 		PatchMargin:          4,
 		MatchMaxBits:         32,
+		UnifiedContext:       3,
+		TerminalTheme:        TerminalTheme16,
+		TerminalLineNumbers:  true,
 	}
 }
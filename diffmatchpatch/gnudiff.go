@@ -0,0 +1,530 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"math"
+	"strings"
+)
+
+// diffLinesGNU computes a diff of the two texts line by line the way GNU diff does and returns one Diff per line.
+// The identical lines at both ends are set aside except for the horizon lines next to the changes, the lines which would only confuse the comparison are discarded, the remaining lines are compared with Myers' algorithm and the changes are shifted into their final place.
+// Within every block of changes all deletions come before all insertions.
+// This follows analyze.c and io.c of GNU diffutils and diffseq.h of gnulib.
+func diffLinesGNU(text1, text2 string, horizon int) []Diff {
+	lines := [2][]string{diffLinesSplit(text1), diffLinesSplit(text2)}
+	equivs, numbers := diffLinesEquivs(lines)
+
+	// Identical lines at the start, of which up to horizon lines are still compared.
+	prefix := 0
+	for prefix < len(lines[0]) && prefix < len(lines[1]) && equivs[0][prefix] == equivs[1][prefix] {
+		prefix++
+	}
+	prefix = max(0, prefix-horizon)
+	// Identical lines at the end, which do not overlap the lines set aside at the start.
+	suffix := 0
+	for suffix < len(lines[0])-prefix && suffix < len(lines[1])-prefix && equivs[0][len(lines[0])-1-suffix] == equivs[1][len(lines[1])-1-suffix] {
+		suffix++
+	}
+	suffix = max(0, suffix-horizon)
+
+	var body [2][]int
+	var changed [2][]bool
+	for f := range body {
+		body[f] = equivs[f][prefix : len(equivs[f])-suffix]
+		// changed[f][i+1] tells whether line i of the body changed, with an unchanged line before and after the body.
+		changed[f] = make([]bool, len(body[f])+2)
+	}
+
+	// Lines which are discarded are changed, the others are compared.
+	counts := [2][]int{make([]int, numbers+1), make([]int, numbers+1)}
+	for f := range body {
+		for _, equiv := range body[f] {
+			counts[f][equiv]++
+		}
+	}
+	c := &gnuComparison{}
+	for f := range body {
+		for i, discarded := range gnuDiscardConfusingLines(body[f], counts[1-f]) {
+			if discarded {
+				changed[f][i+1] = true
+			} else {
+				c.vec[f] = append(c.vec[f], body[f][i])
+				c.realIndexes[f] = append(c.realIndexes[f], i)
+			}
+		}
+	}
+	c.changed = changed
+
+	// The diagonals range from -len(c.vec[1])-1 to len(c.vec[0])+1.
+	diags := len(c.vec[0]) + len(c.vec[1]) + 3
+	c.fdiag = make([]int, diags)
+	c.bdiag = make([]int, diags)
+	c.offset = len(c.vec[1]) + 1
+	// Give up on finding the shortest diff at roughly the square root of the size of the input, but not before the cost of 4096.
+	c.tooExpensive = 1
+	for ; diags != 0; diags >>= 2 {
+		c.tooExpensive <<= 1
+	}
+	c.tooExpensive = max(4096, c.tooExpensive)
+	c.compareSeq(0, len(c.vec[0]), 0, len(c.vec[1]), false)
+
+	gnuShiftBoundaries(body, changed)
+
+	var diffs []Diff
+	for _, line := range lines[0][:prefix] {
+		diffs = append(diffs, Diff{DiffEqual, line})
+	}
+	diffs = append(diffs, diffLinesChanged([2][]string{lines[0][prefix : len(lines[0])-suffix], lines[1][prefix : len(lines[1])-suffix]}, changed)...)
+	for _, line := range lines[0][len(lines[0])-suffix:] {
+		diffs = append(diffs, Diff{DiffEqual, line})
+	}
+
+	return diffs
+}
+
+// diffShiftLines shifts the changes of a diff with one Diff per line into the place where GNU diff puts them.
+func diffShiftLines(lines []Diff) []Diff {
+	var texts [2][]string
+	var changed [2][]bool
+	for f := range changed {
+		changed[f] = []bool{false}
+	}
+	for _, aDiff := range lines {
+		if aDiff.Type != DiffInsert {
+			texts[0] = append(texts[0], aDiff.Text)
+			changed[0] = append(changed[0], aDiff.Type == DiffDelete)
+		}
+		if aDiff.Type != DiffDelete {
+			texts[1] = append(texts[1], aDiff.Text)
+			changed[1] = append(changed[1], aDiff.Type == DiffInsert)
+		}
+	}
+	for f := range changed {
+		changed[f] = append(changed[f], false)
+	}
+
+	equivs, _ := diffLinesEquivs(texts)
+	gnuShiftBoundaries(equivs, changed)
+
+	return diffLinesChanged(texts, changed)
+}
+
+// diffLinesSplit splits a text into its lines, each with its newline.
+func diffLinesSplit(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLinesEquivs numbers the distinct lines of both texts from 1 and returns the numbers of the lines of each text and how many distinct lines there are.
+func diffLinesEquivs(lines [2][]string) ([2][]int, int) {
+	numbers := map[string]int{}
+	var equivs [2][]int
+	for f := range lines {
+		equivs[f] = make([]int, len(lines[f]))
+		for i, line := range lines[f] {
+			number, ok := numbers[line]
+			if !ok {
+				number = len(numbers) + 1
+				numbers[line] = number
+			}
+			equivs[f][i] = number
+		}
+	}
+	return equivs, len(numbers)
+}
+
+// diffLinesChanged turns the lines of both texts into one Diff per line, given which lines changed with the same layout as in gnuShiftBoundaries.
+// Within every block of changes all deletions come before all insertions.
+func diffLinesChanged(lines [2][]string, changed [2][]bool) []Diff {
+	diffs := make([]Diff, 0, max(len(lines[0]), len(lines[1])))
+	i, j := 0, 0
+	for i < len(lines[0]) || j < len(lines[1]) {
+		if i < len(lines[0]) && changed[0][i+1] {
+			diffs = append(diffs, Diff{DiffDelete, lines[0][i]})
+			i++
+		} else if j < len(lines[1]) && changed[1][j+1] {
+			diffs = append(diffs, Diff{DiffInsert, lines[1][j]})
+			j++
+		} else {
+			diffs = append(diffs, Diff{DiffEqual, lines[0][i]})
+			i++
+			j++
+		}
+	}
+	return diffs
+}
+
+// gnuDiscardConfusingLines tells which lines GNU diff leaves out of the comparison, given how often every line occurs in the other text.
+// These are the lines which occur nowhere in the other text, and runs of lines which occur there so often that they would only confuse the comparison.
+func gnuDiscardConfusingLines(equivs []int, otherCounts []int) []bool {
+	const (
+		keep = iota
+		discard
+		provisional
+	)
+
+	// Lines which occur more often than roughly the square root of the number of lines are discarded provisionally.
+	many := 5
+	for tem := len(equivs) / 64 >> 2; tem > 0; tem >>= 2 {
+		many *= 2
+	}
+	discards := make([]int, len(equivs))
+	for i, equiv := range equivs {
+		if otherCounts[equiv] == 0 {
+			discards[i] = discard
+		} else if otherCounts[equiv] > many {
+			discards[i] = provisional
+		}
+	}
+
+	// Provisional lines are only discarded within a run of discarded lines which starts and ends with a line which is not provisional.
+	for i := 0; i < len(discards); i++ {
+		if discards[i] == provisional {
+			discards[i] = keep
+			continue
+		} else if discards[i] == keep {
+			continue
+		}
+
+		// Find the end of this run and count its provisional lines, of which those at its end are kept.
+		j := i
+		provisionals := 0
+		for ; j < len(discards) && discards[j] != keep; j++ {
+			if discards[j] == provisional {
+				provisionals++
+			}
+		}
+		for j > i && discards[j-1] == provisional {
+			j--
+			discards[j] = keep
+			provisionals--
+		}
+		length := j - i
+
+		if provisionals*4 > length {
+			// Too many provisional lines, keep them all.
+			for ; j > i; j-- {
+				if discards[j-1] == provisional {
+					discards[j-1] = keep
+				}
+			}
+			continue
+		}
+
+		// Keep any run of at least minimum provisional lines, which is roughly the square root of a quarter of the run.
+		minimum := 1
+		for tem := length >> 4; tem > 0; tem >>= 2 {
+			minimum <<= 1
+		}
+		minimum++
+		consecutive := 0
+		for j := 0; j < length; j++ {
+			if discards[i+j] != provisional {
+				consecutive = 0
+				continue
+			}
+			consecutive++
+			if consecutive == minimum {
+				// Go back to the start of the run to keep all of it.
+				j -= consecutive
+			} else if consecutive > minimum {
+				discards[i+j] = keep
+			}
+		}
+
+		// Keep the provisional lines at the start until three discarded lines in a row, or a discarded line at least eight lines in.
+		consecutive = 0
+		for j := 0; j < length; j++ {
+			if j >= 8 && discards[i+j] == discard {
+				break
+			}
+			if discards[i+j] == provisional {
+				discards[i+j] = keep
+				consecutive = 0
+			} else if discards[i+j] == keep {
+				consecutive = 0
+			} else {
+				consecutive++
+			}
+			if consecutive == 3 {
+				break
+			}
+		}
+
+		// The same at the end of the run.
+		i += length - 1
+		consecutive = 0
+		for j := 0; j < length; j++ {
+			if j >= 8 && discards[i-j] == discard {
+				break
+			}
+			if discards[i-j] == provisional {
+				discards[i-j] = keep
+				consecutive = 0
+			} else if discards[i-j] == keep {
+				consecutive = 0
+			} else {
+				consecutive++
+			}
+			if consecutive == 3 {
+				break
+			}
+		}
+	}
+
+	discarded := make([]bool, len(discards))
+	for i, d := range discards {
+		discarded[i] = d != keep
+	}
+	return discarded
+}
+
+// gnuComparison is the state of GNU diff's comparison of the lines which were not discarded.
+type gnuComparison struct {
+	// vec holds the lines of both texts which are compared, and realIndexes their indexes among all lines.
+	vec         [2][]int
+	realIndexes [2][]int
+	changed     [2][]bool
+
+	// fdiag and bdiag hold the furthest x reached on every diagonal x-y by the forward and the backward search, shifted by offset.
+	fdiag        []int
+	bdiag        []int
+	offset       int
+	tooExpensive int
+}
+
+// compareSeq marks the lines which changed between vec[0][xoff:xlim] and vec[1][yoff:ylim] by splitting the problem at the middle of a shortest edit script.
+func (c *gnuComparison) compareSeq(xoff, xlim, yoff, ylim int, findMinimal bool) {
+	xv, yv := c.vec[0], c.vec[1]
+	for {
+		for xoff < xlim && yoff < ylim && xv[xoff] == yv[yoff] {
+			xoff++
+			yoff++
+		}
+		for xoff < xlim && yoff < ylim && xv[xlim-1] == yv[ylim-1] {
+			xlim--
+			ylim--
+		}
+
+		if xoff == xlim {
+			for ; yoff < ylim; yoff++ {
+				c.changed[1][c.realIndexes[1][yoff]+1] = true
+			}
+			return
+		}
+		if yoff == ylim {
+			for ; xoff < xlim; xoff++ {
+				c.changed[0][c.realIndexes[0][xoff]+1] = true
+			}
+			return
+		}
+
+		xmid, ymid, loMinimal, hiMinimal := c.diag(xoff, xlim, yoff, ylim, findMinimal)
+		c.compareSeq(xoff, xmid, yoff, ymid, loMinimal)
+		xoff, yoff, findMinimal = xmid, ymid, hiMinimal
+	}
+}
+
+// diag finds the middle snake of a shortest edit script between vec[0][xoff:xlim] and vec[1][yoff:ylim] by searching forward and backward at the same time.
+// Unless findMinimal is set, it gives up when the search becomes too expensive and returns the point which got furthest instead.
+// It also returns whether each half of the problem should still be solved minimally.
+func (c *gnuComparison) diag(xoff, xlim, yoff, ylim int, findMinimal bool) (int, int, bool, bool) {
+	fd, bd, o := c.fdiag, c.bdiag, c.offset
+	xv, yv := c.vec[0], c.vec[1]
+	dmin, dmax := xoff-ylim, xlim-yoff
+	fmid, bmid := xoff-yoff, xlim-ylim
+	fmin, fmax := fmid, fmid
+	bmin, bmax := bmid, bmid
+	// Whether the end is on an odd diagonal seen from the start.
+	odd := (fmid-bmid)&1 != 0
+
+	fd[o+fmid] = xoff
+	bd[o+bmid] = xlim
+
+	for cost := 1; ; cost++ {
+		// Extend the forward search by one edit on every diagonal.
+		if fmin > dmin {
+			fmin--
+			fd[o+fmin-1] = -1
+		} else {
+			fmin++
+		}
+		if fmax < dmax {
+			fmax++
+			fd[o+fmax+1] = -1
+		} else {
+			fmax--
+		}
+		for d := fmax; d >= fmin; d -= 2 {
+			x := fd[o+d-1] + 1
+			if fd[o+d-1] < fd[o+d+1] {
+				x = fd[o+d+1]
+			}
+			y := x - d
+			for x < xlim && y < ylim && xv[x] == yv[y] {
+				x++
+				y++
+			}
+			fd[o+d] = x
+			if odd && bmin <= d && d <= bmax && bd[o+d] <= x {
+				return x, y, true, true
+			}
+		}
+
+		// Extend the backward search the same way.
+		if bmin > dmin {
+			bmin--
+			bd[o+bmin-1] = math.MaxInt
+		} else {
+			bmin++
+		}
+		if bmax < dmax {
+			bmax++
+			bd[o+bmax+1] = math.MaxInt
+		} else {
+			bmax--
+		}
+		for d := bmax; d >= bmin; d -= 2 {
+			x := bd[o+d+1] - 1
+			if bd[o+d-1] < bd[o+d+1] {
+				x = bd[o+d-1]
+			}
+			y := x - d
+			for xoff < x && yoff < y && xv[x-1] == yv[y-1] {
+				x--
+				y--
+			}
+			bd[o+d] = x
+			if !odd && fmin <= d && d <= fmax && x <= fd[o+d] {
+				return x, y, true, true
+			}
+		}
+
+		if findMinimal || cost < c.tooExpensive {
+			continue
+		}
+
+		// Give up and take the forward diagonal which maximizes x+y or the backward one which minimizes it, whichever got further.
+		fxybest, fxbest := -1, 0
+		for d := fmax; d >= fmin; d -= 2 {
+			x := min(fd[o+d], xlim)
+			y := x - d
+			if ylim < y {
+				x, y = ylim+d, ylim
+			}
+			if fxybest < x+y {
+				fxybest, fxbest = x+y, x
+			}
+		}
+		bxybest, bxbest := math.MaxInt, 0
+		for d := bmax; d >= bmin; d -= 2 {
+			x := max(xoff, bd[o+d])
+			y := x - d
+			if y < yoff {
+				x, y = yoff+d, yoff
+			}
+			if x+y < bxybest {
+				bxybest, bxbest = x+y, x
+			}
+		}
+		if (xlim+ylim)-bxybest < fxybest-(xoff+yoff) {
+			return fxbest, fxybest - fxbest, true, false
+		}
+		return bxbest, bxybest - bxbest, false, true
+	}
+}
+
+// gnuShiftBoundaries shifts the runs of changed lines of both texts as GNU diff does to make the diff prettier.
+// A run is first shifted up and then down as far as the unchanged lines around it allow, merging with the runs it reaches, and then back up to end next to a run of changes in the other text if it passed one.
+// changed[f][i+1] tells whether line i of text f changed, and changed[f][0] and the last element are false.
+func gnuShiftBoundaries(equivs [2][]int, changed [2][]bool) {
+	for f := range changed {
+		ch, other, eq := changed[f], changed[1-f], equivs[f]
+		// i is a line of this text and j the corresponding line of the other text.
+		i, j, iEnd := 0, 0, len(eq)
+		for {
+			// Find the start of the next run of changes.
+			for i < iEnd && !ch[i+1] {
+				for other[j+1] {
+					j++
+				}
+				j++
+				i++
+			}
+			if i == iEnd {
+				break
+			}
+			start := i
+
+			// Find its end.
+			for i++; ch[i+1]; i++ {
+			}
+			for other[j+1] {
+				j++
+			}
+
+			// Where the run ends next to a run of changes in the other text, or iEnd if it never does.
+			var corresponding int
+			for {
+				length := i - start
+
+				// Shift the run up as long as the line before it matches its last line, merging it with the runs before it.
+				for start > 0 && eq[start-1] == eq[i-1] {
+					start--
+					ch[start+1] = true
+					i--
+					ch[i+1] = false
+					for ch[start] {
+						start--
+					}
+					for j--; other[j+1]; j-- {
+					}
+				}
+
+				corresponding = iEnd
+				if other[j] {
+					corresponding = i
+				}
+
+				// Shift the run down as long as its first line matches the line after it, merging it with the runs after it.
+				for i != iEnd && eq[start] == eq[i] {
+					ch[start+1] = false
+					start++
+					ch[i+1] = true
+					i++
+					for ch[i+1] {
+						i++
+					}
+					for j++; other[j+1]; j++ {
+						corresponding = i
+					}
+				}
+
+				// Repeat while the run grew by merging.
+				if length == i-start {
+					break
+				}
+			}
+
+			// Shift the run back up to where it ends next to a run of changes in the other text.
+			for corresponding < i {
+				start--
+				ch[start+1] = true
+				i--
+				ch[i+1] = false
+				for j--; other[j+1]; j-- {
+				}
+			}
+		}
+	}
+}
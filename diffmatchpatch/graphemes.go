@@ -0,0 +1,211 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// graphemeClass is the grapheme cluster break property of a rune as far as TokenizeGraphemes distinguishes them.
+type graphemeClass int8
+
+const (
+	graphemeOther graphemeClass = iota
+	graphemeCR
+	graphemeLF
+	graphemeControl
+	graphemeExtend
+	graphemeZWJ
+	graphemeSpacingMark
+	graphemeRegionalIndicator
+	graphemePictographic
+	graphemeL
+	graphemeV
+	graphemeT
+	graphemeLV
+	graphemeLVT
+)
+
+// graphemeClassOf returns the grapheme cluster break property of a rune.
+func graphemeClassOf(r rune) graphemeClass {
+	if r < utf8.RuneSelf {
+		// ASCII (speedup).
+		switch {
+		case r == '\r':
+			return graphemeCR
+		case r == '\n':
+			return graphemeLF
+		case r < ' ' || r == '\u007f':
+			return graphemeControl
+		}
+		return graphemeOther
+	}
+
+	switch {
+	case r == '\u200d':
+		return graphemeZWJ
+	case r == '\u200c', r >= '\U0001f3fb' && r <= '\U0001f3ff', r >= '\U000e0020' && r <= '\U000e007f':
+		// Zero width non-joiner, emoji modifiers and tags.
+		return graphemeExtend
+	case r >= '\U0001f1e6' && r <= '\U0001f1ff':
+		return graphemeRegionalIndicator
+	case r >= '\u1100' && r <= '\u115f', r >= '\ua960' && r <= '\ua97c':
+		return graphemeL
+	case r >= '\u1160' && r <= '\u11a7', r >= '\ud7b0' && r <= '\ud7c6':
+		return graphemeV
+	case r >= '\u11a8' && r <= '\u11ff', r >= '\ud7cb' && r <= '\ud7fb':
+		return graphemeT
+	case r >= '\uac00' && r <= '\ud7a3':
+		// Precomposed Hangul syllables come with or without a trailing consonant.
+		if (r-'\uac00')%28 == 0 {
+			return graphemeLV
+		}
+		return graphemeLVT
+	case unicode.In(r, unicode.Mn, unicode.Me):
+		return graphemeExtend
+	case unicode.In(r, unicode.Mc):
+		return graphemeSpacingMark
+	case unicode.In(r, unicode.Cc, unicode.Cf, unicode.Zl, unicode.Zp):
+		return graphemeControl
+	case isPictographic(r):
+		return graphemePictographic
+	}
+
+	return graphemeOther
+}
+
+// isPictographic approximates the Extended_Pictographic property of emoji.
+func isPictographic(r rune) bool {
+	switch {
+	case r == '\u00a9', r == '\u00ae', r == '\u203c', r == '\u2049', r == '\u2122', r == '\u2139', r == '\u3030', r == '\u303d', r == '\u3297', r == '\u3299':
+		return true
+	case r >= '\u2194' && r <= '\u21aa', r >= '\u231a' && r <= '\u23ff', r >= '\u25aa' && r <= '\u27bf', r >= '\u2934' && r <= '\u2935', r >= '\u2b05' && r <= '\u2b55':
+		return true
+	case r >= '\U0001f000' && r <= '\U0001faff':
+		return true
+	}
+	return false
+}
+
+// TokenizeGraphemes splits a text into extended grapheme clusters, approximating Unicode Standard Annex #29.
+// A cluster is what a reader perceives as one character, such as a letter along with its combining marks, a Hangul syllable, a flag or an emoji sequence joined by zero width joiners.
+// The tokens add up to the text, invalid UTF-8 sequences are tokens of their own.
+func TokenizeGraphemes(text string) []string {
+	var tokens []string
+	start := 0
+	before := graphemeOther
+	// Number of regional indicators in a row, and whether the cluster so far is an emoji followed by extending runes.
+	regionalIndicators := 0
+	pictographic := false
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		after := graphemeClassOf(r)
+		if r == utf8.RuneError && size == 1 {
+			// Invalid UTF-8 is kept apart from its neighbours.
+			after = graphemeControl
+		}
+
+		join := false
+		switch {
+		case i == 0:
+			join = true
+		case before == graphemeCR && after == graphemeLF:
+			join = true
+		case before == graphemeCR || before == graphemeLF || before == graphemeControl:
+			join = false
+		case after == graphemeCR || after == graphemeLF || after == graphemeControl:
+			join = false
+		case before == graphemeL && (after == graphemeL || after == graphemeV || after == graphemeLV || after == graphemeLVT):
+			join = true
+		case (before == graphemeLV || before == graphemeV) && (after == graphemeV || after == graphemeT):
+			join = true
+		case (before == graphemeLVT || before == graphemeT) && after == graphemeT:
+			join = true
+		case after == graphemeExtend || after == graphemeZWJ || after == graphemeSpacingMark:
+			join = true
+		case before == graphemeZWJ && after == graphemePictographic:
+			join = pictographic
+		case before == graphemeRegionalIndicator && after == graphemeRegionalIndicator:
+			// Flags are pairs of regional indicators.
+			join = regionalIndicators%2 == 1
+		}
+
+		if !join {
+			tokens = append(tokens, text[start:i])
+			start = i
+			pictographic = false
+		}
+
+		if after == graphemeRegionalIndicator {
+			regionalIndicators++
+		} else {
+			regionalIndicators = 0
+		}
+		if after == graphemePictographic {
+			pictographic = true
+		} else if after != graphemeExtend && after != graphemeZWJ {
+			pictographic = false
+		}
+		before = after
+		i += size
+	}
+	if len(text) > 0 {
+		tokens = append(tokens, text[start:])
+	}
+
+	return tokens
+}
+
+// runeWidth returns the number of terminal cells which a rune takes up, approximating Unicode Standard Annex #11.
+// East Asian wide and fullwidth characters as well as emoji take up 2 cells, marks, controls and other invisible runes none, and any other rune 1 cell.
+func runeWidth(r rune) int {
+	if r < utf8.RuneSelf {
+		// ASCII (speedup).
+		if r < ' ' || r == '\u007f' {
+			return 0
+		}
+		return 1
+	}
+
+	switch {
+	case r >= '\u1160' && r <= '\u11ff', r == '\u200b':
+		// Hangul vowels and trailing consonants, which join a leading consonant, and the zero width space.
+		return 0
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cc, unicode.Cf):
+		return 0
+	case r >= '\u1100' && r <= '\u115f', r >= '\u2e80' && r <= '\u303e', r >= '\u3041' && r <= '\u33ff', r >= '\u3400' && r <= '\u4dbf', r >= '\u4e00' && r <= '\u9fff', r >= '\ua000' && r <= '\ua4cf', r >= '\ua960' && r <= '\ua97f', r >= '\uac00' && r <= '\ud7a3':
+		// Hangul, CJK and Yi.
+		return 2
+	case r >= '\uf900' && r <= '\ufaff', r >= '\ufe10' && r <= '\ufe19', r >= '\ufe30' && r <= '\ufe6f', r >= '\uff00' && r <= '\uff60', r >= '\uffe0' && r <= '\uffe6':
+		// CJK compatibility ideographs, vertical and small forms, and fullwidth forms.
+		return 2
+	case r >= '\U0001f1e6' && r <= '\U0001f1ff', r >= '\U0001f300' && r <= '\U0001f64f', r >= '\U0001f680' && r <= '\U0001f6ff', r >= '\U0001f900' && r <= '\U0001f9ff', r >= '\U0001fa70' && r <= '\U0001faff':
+		// Regional indicators and emoji.
+		return 2
+	case r >= '\U00020000' && r <= '\U0003fffd':
+		// Supplementary and tertiary ideographic planes.
+		return 2
+	}
+
+	return 1
+}
+
+// graphemeWidth returns the number of terminal cells which a grapheme cluster takes up, which is the width of its widest rune.
+// Emoji presentation selectors make a cluster 2 cells wide.
+func graphemeWidth(grapheme string) int {
+	width := 0
+	for _, r := range grapheme {
+		if r == '\ufe0f' {
+			return 2
+		}
+		width = max(width, runeWidth(r))
+	}
+	return width
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenizeGraphemes(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text string
+
+		Expected []string
+	}
+
+	for i, tc := range []TestCase{
+		{"Null case", "", nil},
+		{"ASCII", "ab c", []string{"a", "b", " ", "c"}},
+		{"Line breaks", "a\r\n\n\rb", []string{"a", "\r\n", "\n", "\r", "b"}},
+		{"Combining marks", "cafe\u0301 Z\u0334\u0316\u0351", []string{"c", "a", "f", "e\u0301", " ", "Z\u0334\u0316\u0351"}},
+		{"Spacing marks", "\u0915\u093f\u0928\u094d", []string{"\u0915\u093f", "\u0928\u094d"}},
+		{"Hangul jamo", "\u1100\u1161\u11a8\uac00\u11a8\uac01", []string{"\u1100\u1161\u11a8", "\uac00\u11a8", "\uac01"}},
+		{"Regional indicators", "\U0001f1e9\U0001f1ea\U0001f1eb\U0001f1f7\U0001f1ee", []string{"\U0001f1e9\U0001f1ea", "\U0001f1eb\U0001f1f7", "\U0001f1ee"}},
+		{"Emoji modifiers", "\U0001f44d\U0001f3fd!", []string{"\U0001f44d\U0001f3fd", "!"}},
+		{"Emoji sequences", "\U0001f468\u200d\U0001f469\u200d\U0001f467 \U0001f3f3\ufe0f\u200d\U0001f308", []string{"\U0001f468\u200d\U0001f469\u200d\U0001f467", " ", "\U0001f3f3\ufe0f\u200d\U0001f308"}},
+		{"Joiner without emoji", "a\u200d\U0001f308", []string{"a\u200d", "\U0001f308"}},
+		{"Control characters", "a\u0301\x00\u0301", []string{"a\u0301", "\x00", "\u0301"}},
+		{"Invalid UTF-8", "e\xff\u0301", []string{"e", "\xff", "\u0301"}},
+	} {
+		actual := TokenizeGraphemes(tc.Text)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Text, strings.Join(actual, ""), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestGraphemeWidth(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Grapheme string
+
+		Expected int
+	}
+
+	for i, tc := range []TestCase{
+		{"Null case", "", 0},
+		{"ASCII", "a", 1},
+		{"Control character", "\x1b", 0},
+		{"Latin", "\u00e9", 1},
+		{"Combining marks", "e\u0301\u0316", 1},
+		{"Han", "\u6f22", 2},
+		{"Hiragana", "\u3042", 2},
+		{"Hangul jamo", "\u1100\u1161\u11a8", 2},
+		{"Hangul syllable", "\ud55c", 2},
+		{"Fullwidth form", "\uff21", 2},
+		{"Halfwidth form", "\uff71", 1},
+		{"Zero width space", "\u200b", 0},
+		{"Flag", "\U0001f1e9\U0001f1ea", 2},
+		{"Emoji sequence", "\U0001f468\u200d\U0001f469\u200d\U0001f467", 2},
+		{"Emoji presentation", "\u2764\ufe0f", 2},
+		{"Text presentation", "\u2764", 1},
+		{"Supplementary ideograph", "\U00020000", 2},
+	} {
+		assert.Equal(t, tc.Expected, graphemeWidth(tc.Grapheme), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"context"
+)
+
+// histogramMaxChainLength is the number of occurrences beyond which an element is not considered as an anchor by the histogram diff.
+const histogramMaxChainLength = 64
+
+// histogramRegion is a region which is equal in both texts, i.e. text1[begin1:end1] equals text2[begin2:end2].
+type histogramRegion struct {
+	begin1 int
+	end1   int
+	begin2 int
+	end2   int
+}
+
+// diffHistogram finds the differences between two rune slices using the histogram diff algorithm of JGit.
+// The longest common region among those made up of the least frequent elements of text1 is used as an anchor, and the texts before and after it are diffed recursively.
+// Falls back to diffBisect if every common element occurs more than histogramMaxChainLength times.
+func (dmp *DiffMatchPatch) diffHistogram(ctx context.Context, text1, text2 []rune) []Diff {
+	lcs, ok := histogramLCS(text1, text2)
+	if !ok {
+		return dmp.diffBisect(ctx, text1, text2)
+	} else if lcs.begin1 == lcs.end1 {
+		// Nothing in common.
+		return []Diff{
+			Diff{DiffDelete, string(text1)},
+			Diff{DiffInsert, string(text2)},
+		}
+	}
+
+	diffs := dmp.diffMainRunes(ctx, text1[:lcs.begin1], text2[:lcs.begin2], false)
+	diffs = append(diffs, Diff{DiffEqual, string(text1[lcs.begin1:lcs.end1])})
+	diffs = append(diffs, dmp.diffMainRunes(ctx, text1[lcs.end1:], text2[lcs.end2:], false)...)
+
+	return diffs
+}
+
+// histogramLCS finds the longest common region of the two slices among those whose least frequent element occurs least often in text1.
+// Returns an empty region if there are no common elements, and false if every common element occurs more than histogramMaxChainLength times.
+func histogramLCS[T comparable](text1, text2 []T) (histogramRegion, bool) {
+	// Positions of every element in text1, in ascending order.
+	occurrences := map[T][]int{}
+	for i, r := range text1 {
+		occurrences[r] = append(occurrences[r], i)
+	}
+
+	var lcs histogramRegion
+	lcsCount := histogramMaxChainLength + 1
+	hasCommon := false
+
+	for pointer2 := 0; pointer2 < len(text2); {
+		next2 := pointer2 + 1
+		positions, ok := occurrences[text2[pointer2]]
+		if !ok {
+			pointer2 = next2
+			continue
+		}
+		hasCommon = true
+		if len(positions) > lcsCount {
+			// Too common to be a better anchor than the current one.
+			pointer2 = next2
+			continue
+		}
+
+		for k := 0; k < len(positions); {
+			region := histogramRegion{positions[k], positions[k] + 1, pointer2, pointer2 + 1}
+			// Lowest number of occurrences of any element within the region.
+			count := len(positions)
+
+			for region.begin1 > 0 && region.begin2 > 0 && text1[region.begin1-1] == text2[region.begin2-1] {
+				region.begin1--
+				region.begin2--
+				if count > 1 {
+					count = min(count, len(occurrences[text1[region.begin1]]))
+				}
+			}
+			for region.end1 < len(text1) && region.end2 < len(text2) && text1[region.end1] == text2[region.end2] {
+				if count > 1 {
+					count = min(count, len(occurrences[text1[region.end1]]))
+				}
+				region.end1++
+				region.end2++
+			}
+
+			if next2 < region.end2 {
+				next2 = region.end2
+			}
+			if lcs.end1-lcs.begin1 < region.end1-region.begin1 || count < lcsCount {
+				// The region is longer or consists of rarer elements than the current one.
+				lcs = region
+				lcsCount = count
+			}
+
+			// Skip the occurrences within the region which was just examined.
+			k++
+			for k < len(positions) && positions[k] < region.end1 {
+				k++
+			}
+		}
+
+		pointer2 = next2
+	}
+
+	if hasCommon && lcsCount > histogramMaxChainLength {
+		return histogramRegion{}, false
+	}
+
+	return lcs, true
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogramLCS(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+
+		Expected   histogramRegion
+		ExpectedOk bool
+	}
+
+	for i, tc := range []TestCase{
+		{"Nothing in common", "abc", "xyz", histogramRegion{}, true},
+		{"Common middle", "abcd", "xbcy", histogramRegion{1, 3, 1, 3}, true},
+		{"Rare elements win", "aab", "ab", histogramRegion{1, 3, 0, 2}, true},
+		{"Too frequent", strings.Repeat("a", histogramMaxChainLength+1), "a", histogramRegion{}, false},
+	} {
+		actual, actualOk := histogramLCS([]rune(tc.Text1), []rune(tc.Text2))
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedOk, actualOk, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestDiffHistogram(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+
+		Expected string
+	}
+
+	dmp := New()
+	dmp.DiffAlgorithm = AlgorithmHistogram
+
+	// Expected outputs were produced by "git diff --no-index --histogram --no-indent-heuristic".
+	for i, tc := range []TestCase{
+		{"Inserted function", "void func1() {\n    x += 1\n}\n\nvoid func2() {\n    x += 2\n}\n", "void func1() {\n    x += 1\n}\n\nvoid functhreehalves() {\n    x += 1.5\n}\n\nvoid func2() {\n    x += 2\n}\n", "@@ -2,6 +2,10 @@\n     x += 1\n }\n \n+void functhreehalves() {\n+    x += 1.5\n+}\n+\n void func2() {\n     x += 2\n }\n"},
+		{"Rare lines win", "\nx = 1\nx = 1\nx = 1\nu3\nu0\nreturn\n", "x = 1\nx = 1\n\nu3\nreturn\n", "@@ -1,7 +1,5 @@\n+x = 1\n+x = 1\n \n-x = 1\n-x = 1\n-x = 1\n u3\n-u0\n return\n"},
+	} {
+		var actual []string
+		for _, hunk := range dmp.DiffToUnifiedHunks(dmp.diffLinesExact(tc.Text1, tc.Text2)) {
+			actual = append(actual, hunk.String())
+		}
+		assert.Equal(t, tc.Expected, strings.Join(actual, ""), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Character mode.
+	assert.Equal(t, []Diff{{DiffEqual, "a"}, {DiffDelete, "x"}, {DiffInsert, "y"}, {DiffEqual, "b"}}, dmp.DiffMain("axb", "ayb", false))
+
+	// Lines which repeat, though less often than histogramMaxChainLength, so that the histogram diff anchors on the rare ones instead of falling back to bisection.
+	// The expected output follows "git diff --no-index --histogram", which differs from a minimal diff.
+	var text1, text2, expected bytes.Buffer
+	_, _ = expected.WriteString("@@ -1,3500 +1,2500 @@\n")
+	for i := 0; i < 500; i++ {
+		_, _ = fmt.Fprintf(&text1, "\nx = %d\nx = %d\nx = %d\nu%d\nv%d\nreturn\n", i, i, i, i, i)
+		_, _ = fmt.Fprintf(&text2, "x = %d\nx = %d\n\nu%d\nreturn\n", i, i, i)
+		_, _ = fmt.Fprintf(&expected, "+x = %d\n+x = %d\n \n-x = %d\n-x = %d\n-x = %d\n u%d\n-v%d\n return\n", i, i, i, i, i, i, i)
+	}
+
+	lines1, lines2, _ := dmp.DiffLinesToRunes(text1.String(), text2.String())
+	_, ok := histogramLCS(lines1, lines2)
+	assert.True(t, ok)
+
+	dmp.DiffTimeout = 0
+	hunks := dmp.DiffToUnifiedHunks(dmp.diffLinesExact(text1.String(), text2.String()))
+	assert.Equal(t, 1, len(hunks))
+	assert.Equal(t, expected.String(), hunks[0].String())
+
+	dmp.DiffAlgorithm = AlgorithmBisect
+	hunks = dmp.DiffToUnifiedHunks(dmp.diffLinesExact(text1.String(), text2.String()))
+	assert.NotEqual(t, expected.String(), hunks[0].String())
+	dmp.DiffAlgorithm = AlgorithmHistogram
+
+	// Line mode with character refinement.
+	s1, s2 := speedtestTexts()
+	diffs := dmp.DiffMain(s1, s2, true)
+	assert.Equal(t, s1, dmp.DiffText1(diffs))
+	assert.Equal(t, s2, dmp.DiffText2(diffs))
+}
+
+func BenchmarkDiffHistogramLarge(b *testing.B) {
+	s1, s2 := speedtestTexts()
+
+	dmp := New()
+	dmp.DiffAlgorithm = AlgorithmHistogram
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		dmp.DiffMain(s1, s2, true)
+	}
+}
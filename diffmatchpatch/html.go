@@ -0,0 +1,162 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"bytes"
+	"html"
+	"strconv"
+	"strings"
+)
+
+// DiffHTMLStyle is a style sheet for the tables of DiffSideBySideHTML and DiffUnifiedHTML.
+const DiffHTMLStyle = `table.diff { border-collapse: collapse; font-family: monospace; width: 100%; }
+table.diff td { padding: 0 0.5em; vertical-align: top; }
+table.diff td.diff-line-number { color: #999; text-align: right; user-select: none; width: 1%; }
+table.diff td.diff-text { white-space: pre-wrap; word-break: break-all; }
+table.diff td.diff-delete { background: #ffeef0; }
+table.diff td.diff-insert { background: #e6ffed; }
+table.diff td.diff-empty { background: #fafbfc; }
+table.diff del { background: #fdb8c0; text-decoration: none; }
+table.diff ins { background: #acf2bd; text-decoration: none; }
+table.diff span.diff-no-newline { color: #999; }
+table.diff tbody.diff-fold td { background: #f1f8ff; color: #586069; cursor: pointer; }
+table.diff tbody.diff-folded { display: none; }
+table.diff tbody.diff-folded.diff-expanded { display: table-row-group; }
+`
+
+// DiffSideBySideHTML renders a line-based diff as an HTML table with two columns, the old text on the left and the new text on the right, each along with its line numbers.
+// Deleted and inserted lines are paired up in rows, and the words which changed within them are marked by <del> and <ins> elements.
+// Unchanged lines which are more than UnifiedContext lines away from a change are folded into a tbody of class "diff-folded", preceded by a tbody of class "diff-fold" saying how many lines it holds. Expanding them is up to the page, e.g. by adding the class "diff-expanded".
+// The table uses CSS classes rather than inline styles, see DiffHTMLStyle.
+func (dmp *DiffMatchPatch) DiffSideBySideHTML(diffs []Diff) string {
+	return dmp.diffHTML(diffs, true)
+}
+
+// DiffUnifiedHTML renders a line-based diff as an HTML table with one column, showing deleted lines before inserted ones along with the line numbers of both texts.
+// Changed words, folded lines and CSS classes are the same as for DiffSideBySideHTML.
+func (dmp *DiffMatchPatch) DiffUnifiedHTML(diffs []Diff) string {
+	return dmp.diffHTML(diffs, false)
+}
+
+// diffHTML renders a line-based diff as an HTML table with one or two columns.
+func (dmp *DiffMatchPatch) diffHTML(diffs []Diff, sideBySide bool) string {
+	rows := dmp.diffRows(diffs, sideBySide)
+	columns := 3
+	class := "diff diff-unified"
+	if sideBySide {
+		columns = 4
+		class = "diff diff-side-by-side"
+	}
+
+	folded := diffFoldRows(rows, dmp.UnifiedContext)
+
+	var buff bytes.Buffer
+	_, _ = buff.WriteString("<table class=\"" + class + "\">\n")
+	for start := 0; start < len(rows); {
+		end := start
+		for end < len(rows) && folded[end] == folded[start] {
+			end++
+		}
+
+		if folded[start] {
+			lines := "lines"
+			if end-start == 1 {
+				lines = "line"
+			}
+			_, _ = buff.WriteString("<tbody class=\"diff-fold\">\n")
+			_, _ = buff.WriteString("<tr><td colspan=\"" + strconv.Itoa(columns) + "\">" + strconv.Itoa(end-start) + " unchanged " + lines + "</td></tr>\n")
+			_, _ = buff.WriteString("</tbody>\n")
+			_, _ = buff.WriteString("<tbody class=\"diff-folded\">\n")
+		} else {
+			_, _ = buff.WriteString("<tbody>\n")
+		}
+		for _, row := range rows[start:end] {
+			if sideBySide {
+				diffHTMLSideBySideRow(&buff, row)
+			} else {
+				diffHTMLUnifiedRow(&buff, row)
+			}
+		}
+		_, _ = buff.WriteString("</tbody>\n")
+
+		start = end
+	}
+	_, _ = buff.WriteString("</table>\n")
+
+	return buff.String()
+}
+
+// diffHTMLLine renders a line of one text as HTML, given a word-based diff of its text without the newline.
+// The parts of the diff which only belong to the other text are left out, and the ones of the given type are marked as changed.
+func diffHTMLLine(line string, words []Diff, op Operation) string {
+	var buff bytes.Buffer
+	for _, aDiff := range words {
+		text := html.EscapeString(aDiff.Text)
+		switch {
+		case aDiff.Type == DiffEqual:
+			_, _ = buff.WriteString(text)
+		case aDiff.Type != op:
+			continue
+		case op == DiffDelete:
+			_, _ = buff.WriteString("<del>" + text + "</del>")
+		case op == DiffInsert:
+			_, _ = buff.WriteString("<ins>" + text + "</ins>")
+		}
+	}
+	if !strings.HasSuffix(line, "\n") {
+		_, _ = buff.WriteString("<span class=\"diff-no-newline\">" + html.EscapeString(noNewlineMarker) + "</span>")
+	}
+	return buff.String()
+}
+
+// diffHTMLSideBySideRow writes a row with a line number and a line of either text.
+func diffHTMLSideBySideRow(buff *bytes.Buffer, row diffRow) {
+	class1, class2 := "", ""
+	html1, html2 := "", ""
+	if row.Line1 != 0 {
+		html1 = diffHTMLLine(row.Text1, row.Words1, DiffDelete)
+	}
+	if row.Line2 != 0 {
+		html2 = diffHTMLLine(row.Text2, row.Words2, DiffInsert)
+	}
+	if row.Type != DiffEqual {
+		class1, class2 = " diff-delete", " diff-insert"
+		if row.Line1 == 0 {
+			class1 = " diff-empty"
+		}
+		if row.Line2 == 0 {
+			class2 = " diff-empty"
+		}
+	}
+
+	_, _ = buff.WriteString("<tr>")
+	_, _ = buff.WriteString("<td class=\"diff-line-number\">" + diffRowNumber(row.Line1) + "</td>")
+	_, _ = buff.WriteString("<td class=\"diff-text" + class1 + "\">" + html1 + "</td>")
+	_, _ = buff.WriteString("<td class=\"diff-line-number\">" + diffRowNumber(row.Line2) + "</td>")
+	_, _ = buff.WriteString("<td class=\"diff-text" + class2 + "\">" + html2 + "</td>")
+	_, _ = buff.WriteString("</tr>\n")
+}
+
+// diffHTMLUnifiedRow writes a row with the line numbers of both texts and one line.
+func diffHTMLUnifiedRow(buff *bytes.Buffer, row diffRow) {
+	class, text := "", diffHTMLLine(row.Text1, row.Words1, DiffDelete)
+	switch row.Type {
+	case DiffDelete:
+		class = " diff-delete"
+	case DiffInsert:
+		class, text = " diff-insert", diffHTMLLine(row.Text2, row.Words2, DiffInsert)
+	}
+
+	_, _ = buff.WriteString("<tr>")
+	_, _ = buff.WriteString("<td class=\"diff-line-number\">" + diffRowNumber(row.Line1) + "</td>")
+	_, _ = buff.WriteString("<td class=\"diff-line-number\">" + diffRowNumber(row.Line2) + "</td>")
+	_, _ = buff.WriteString("<td class=\"diff-text" + class + "\">" + text + "</td>")
+	_, _ = buff.WriteString("</tr>\n")
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffSideBySideHTML(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1   string
+		Text2   string
+		Context int
+
+		ExpectedSideBySide string
+		ExpectedUnified    string
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{
+			"Null case", "", "", 3,
+			"<table class=\"diff diff-side-by-side\">\n</table>\n",
+			"<table class=\"diff diff-unified\">\n</table>\n",
+		},
+		{
+			"Changed words", "a\nThe quick brown fox\n", "a\nThe quick red fox\nnew\n", 3,
+			"<table class=\"diff diff-side-by-side\">\n<tbody>\n" +
+				"<tr><td class=\"diff-line-number\">1</td><td class=\"diff-text\">a</td><td class=\"diff-line-number\">1</td><td class=\"diff-text\">a</td></tr>\n" +
+				"<tr><td class=\"diff-line-number\">2</td><td class=\"diff-text diff-delete\">The quick <del>brown</del> fox</td><td class=\"diff-line-number\">2</td><td class=\"diff-text diff-insert\">The quick <ins>red</ins> fox</td></tr>\n" +
+				"<tr><td class=\"diff-line-number\"></td><td class=\"diff-text diff-empty\"></td><td class=\"diff-line-number\">3</td><td class=\"diff-text diff-insert\">new</td></tr>\n" +
+				"</tbody>\n</table>\n",
+			"<table class=\"diff diff-unified\">\n<tbody>\n" +
+				"<tr><td class=\"diff-line-number\">1</td><td class=\"diff-line-number\">1</td><td class=\"diff-text\">a</td></tr>\n" +
+				"<tr><td class=\"diff-line-number\">2</td><td class=\"diff-line-number\"></td><td class=\"diff-text diff-delete\">The quick <del>brown</del> fox</td></tr>\n" +
+				"<tr><td class=\"diff-line-number\"></td><td class=\"diff-line-number\">2</td><td class=\"diff-text diff-insert\">The quick <ins>red</ins> fox</td></tr>\n" +
+				"<tr><td class=\"diff-line-number\"></td><td class=\"diff-line-number\">3</td><td class=\"diff-text diff-insert\">new</td></tr>\n" +
+				"</tbody>\n</table>\n",
+		},
+		{
+			"Folded lines", "a\nb\nc\nd\ne\nf\n", "a\nb\nC\nd\ne\nf\n", 1,
+			"<table class=\"diff diff-side-by-side\">\n" +
+				"<tbody class=\"diff-fold\">\n<tr><td colspan=\"4\">1 unchanged line</td></tr>\n</tbody>\n<tbody class=\"diff-folded\">\n" +
+				"<tr><td class=\"diff-line-number\">1</td><td class=\"diff-text\">a</td><td class=\"diff-line-number\">1</td><td class=\"diff-text\">a</td></tr>\n" +
+				"</tbody>\n<tbody>\n" +
+				"<tr><td class=\"diff-line-number\">2</td><td class=\"diff-text\">b</td><td class=\"diff-line-number\">2</td><td class=\"diff-text\">b</td></tr>\n" +
+				"<tr><td class=\"diff-line-number\">3</td><td class=\"diff-text diff-delete\"><del>c</del></td><td class=\"diff-line-number\">3</td><td class=\"diff-text diff-insert\"><ins>C</ins></td></tr>\n" +
+				"<tr><td class=\"diff-line-number\">4</td><td class=\"diff-text\">d</td><td class=\"diff-line-number\">4</td><td class=\"diff-text\">d</td></tr>\n" +
+				"</tbody>\n" +
+				"<tbody class=\"diff-fold\">\n<tr><td colspan=\"4\">2 unchanged lines</td></tr>\n</tbody>\n<tbody class=\"diff-folded\">\n" +
+				"<tr><td class=\"diff-line-number\">5</td><td class=\"diff-text\">e</td><td class=\"diff-line-number\">5</td><td class=\"diff-text\">e</td></tr>\n" +
+				"<tr><td class=\"diff-line-number\">6</td><td class=\"diff-text\">f</td><td class=\"diff-line-number\">6</td><td class=\"diff-text\">f</td></tr>\n" +
+				"</tbody>\n</table>\n",
+			"<table class=\"diff diff-unified\">\n" +
+				"<tbody class=\"diff-fold\">\n<tr><td colspan=\"3\">1 unchanged line</td></tr>\n</tbody>\n<tbody class=\"diff-folded\">\n" +
+				"<tr><td class=\"diff-line-number\">1</td><td class=\"diff-line-number\">1</td><td class=\"diff-text\">a</td></tr>\n" +
+				"</tbody>\n<tbody>\n" +
+				"<tr><td class=\"diff-line-number\">2</td><td class=\"diff-line-number\">2</td><td class=\"diff-text\">b</td></tr>\n" +
+				"<tr><td class=\"diff-line-number\">3</td><td class=\"diff-line-number\"></td><td class=\"diff-text diff-delete\"><del>c</del></td></tr>\n" +
+				"<tr><td class=\"diff-line-number\"></td><td class=\"diff-line-number\">3</td><td class=\"diff-text diff-insert\"><ins>C</ins></td></tr>\n" +
+				"<tr><td class=\"diff-line-number\">4</td><td class=\"diff-line-number\">4</td><td class=\"diff-text\">d</td></tr>\n" +
+				"</tbody>\n" +
+				"<tbody class=\"diff-fold\">\n<tr><td colspan=\"3\">2 unchanged lines</td></tr>\n</tbody>\n<tbody class=\"diff-folded\">\n" +
+				"<tr><td class=\"diff-line-number\">5</td><td class=\"diff-line-number\">5</td><td class=\"diff-text\">e</td></tr>\n" +
+				"<tr><td class=\"diff-line-number\">6</td><td class=\"diff-line-number\">6</td><td class=\"diff-text\">f</td></tr>\n" +
+				"</tbody>\n</table>\n",
+		},
+		{
+			"Escaped without newline", "<a href=\"x\">\n", "<a href=\"y\">", 3,
+			"<table class=\"diff diff-side-by-side\">\n<tbody>\n" +
+				"<tr><td class=\"diff-line-number\">1</td><td class=\"diff-text diff-delete\">&lt;a href=&#34;<del>x</del>&#34;&gt;</td><td class=\"diff-line-number\">1</td><td class=\"diff-text diff-insert\">&lt;a href=&#34;<ins>y</ins>&#34;&gt;<span class=\"diff-no-newline\">\\ No newline at end of file</span></td></tr>\n" +
+				"</tbody>\n</table>\n",
+			"<table class=\"diff diff-unified\">\n<tbody>\n" +
+				"<tr><td class=\"diff-line-number\">1</td><td class=\"diff-line-number\"></td><td class=\"diff-text diff-delete\">&lt;a href=&#34;<del>x</del>&#34;&gt;</td></tr>\n" +
+				"<tr><td class=\"diff-line-number\"></td><td class=\"diff-line-number\">1</td><td class=\"diff-text diff-insert\">&lt;a href=&#34;<ins>y</ins>&#34;&gt;<span class=\"diff-no-newline\">\\ No newline at end of file</span></td></tr>\n" +
+				"</tbody>\n</table>\n",
+		},
+	} {
+		dmp.UnifiedContext = tc.Context
+		diffs := dmp.DiffMain(tc.Text1, tc.Text2, false)
+		assert.Equal(t, tc.ExpectedSideBySide, dmp.DiffSideBySideHTML(diffs), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedUnified, dmp.DiffUnifiedHTML(diffs), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Every line of the corpus shows up once on either side.
+	dmp.UnifiedContext = 3
+	text1, text2 := speedtestTexts()
+	diffs := dmp.DiffMain(text1, text2, false)
+	for _, actual := range []string{dmp.DiffSideBySideHTML(diffs), dmp.DiffUnifiedHTML(diffs)} {
+		assert.Equal(t, strings.Count(actual, "<tr>"), strings.Count(actual, "</tr>"))
+		assert.Equal(t, strings.Count(actual, "<tbody"), strings.Count(actual, "</tbody>"))
+	}
+	assert.Equal(t, len(dmp.diffToLines(diffs)), strings.Count(dmp.DiffUnifiedHTML(diffs), "<td class=\"diff-text"))
+}
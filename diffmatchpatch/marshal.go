@@ -0,0 +1,188 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+// The JSON schema of diffs and patches is:
+//
+//	Operation: "delete" | "equal" | "insert"
+//	Diff:      {"op": Operation, "text": string}
+//	Patch:     {"start1": number, "start2": number, "length1": number, "length2": number, "diffs": [Diff, ...]}
+//
+// Positions and lengths of patches count in the unit which the patches were made with, see PatchUnit.
+// Texts are JSON strings, hence invalid UTF-8 does not survive a round trip.
+// When unmarshalling, operations may also be the numbers -1, 0 and 1 used by the other diff-match-patch implementations, and unknown fields are ignored.
+//
+// As text, an operation is its name from the schema, a diff is its text prefixed by "-", "=" or "+", and a patch is what PatchToText makes of it.
+
+// diffJSON is the JSON representation of a Diff.
+type diffJSON struct {
+	Op   *Operation `json:"op"`
+	Text string     `json:"text"`
+}
+
+// patchJSON is the JSON representation of a Patch.
+type patchJSON struct {
+	Start1  int    `json:"start1"`
+	Start2  int    `json:"start2"`
+	Length1 int    `json:"length1"`
+	Length2 int    `json:"length2"`
+	Diffs   []Diff `json:"diffs"`
+}
+
+// MarshalText encodes an operation as "delete", "equal" or "insert".
+func (op Operation) MarshalText() ([]byte, error) {
+	switch op {
+	case DiffDelete:
+		return []byte("delete"), nil
+	case DiffEqual:
+		return []byte("equal"), nil
+	case DiffInsert:
+		return []byte("insert"), nil
+	}
+	return nil, errors.New("Invalid operation: " + op.String())
+}
+
+// UnmarshalText decodes an operation from "delete", "equal" or "insert".
+func (op *Operation) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "delete":
+		*op = DiffDelete
+	case "equal":
+		*op = DiffEqual
+	case "insert":
+		*op = DiffInsert
+	default:
+		return errors.New("Invalid operation: " + strconv.Quote(string(text)))
+	}
+	return nil
+}
+
+// MarshalJSON encodes an operation as a JSON string of its text.
+func (op Operation) MarshalJSON() ([]byte, error) {
+	text, err := op.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON decodes an operation from a JSON string of its text, or from the numbers -1, 0 and 1.
+func (op *Operation) UnmarshalJSON(data []byte) error {
+	var number int
+	if err := json.Unmarshal(data, &number); err == nil {
+		if number < -1 || number > 1 {
+			return errors.New("Invalid operation: " + string(data))
+		}
+		*op = Operation(number)
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return errors.New("Invalid operation: " + string(data))
+	}
+	return op.UnmarshalText([]byte(text))
+}
+
+// MarshalText encodes a diff as its text prefixed by "-" for a deletion, "=" for an equality or "+" for an insertion.
+func (d Diff) MarshalText() ([]byte, error) {
+	var sign byte
+	switch d.Type {
+	case DiffDelete:
+		sign = '-'
+	case DiffEqual:
+		sign = '='
+	case DiffInsert:
+		sign = '+'
+	default:
+		return nil, errors.New("Invalid operation: " + d.Type.String())
+	}
+	return append([]byte{sign}, d.Text...), nil
+}
+
+// UnmarshalText decodes a diff from its text prefixed by "-", "=" or "+".
+func (d *Diff) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		return errors.New("Empty diff")
+	}
+	switch text[0] {
+	case '-':
+		d.Type = DiffDelete
+	case '=':
+		d.Type = DiffEqual
+	case '+':
+		d.Type = DiffInsert
+	default:
+		return errors.New("Invalid diff operation: " + strconv.Quote(string(text[:1])))
+	}
+	d.Text = string(text[1:])
+	return nil
+}
+
+// MarshalJSON encodes a diff as {"op": Operation, "text": string}.
+func (d Diff) MarshalJSON() ([]byte, error) {
+	return json.Marshal(diffJSON{&d.Type, d.Text})
+}
+
+// UnmarshalJSON decodes a diff from {"op": Operation, "text": string}.
+func (d *Diff) UnmarshalJSON(data []byte) error {
+	var aDiff diffJSON
+	if err := json.Unmarshal(data, &aDiff); err != nil {
+		return err
+	} else if aDiff.Op == nil {
+		return errors.New("Missing diff operation: " + string(data))
+	}
+	d.Type = *aDiff.Op
+	d.Text = aDiff.Text
+	return nil
+}
+
+// MarshalText encodes a patch like PatchToText, i.e. as a header along with URL encoded lines of its diffs.
+func (p Patch) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText decodes a single patch like PatchFromText.
+func (p *Patch) UnmarshalText(text []byte) error {
+	patches, err := New().PatchFromText(string(text))
+	if err != nil {
+		return err
+	} else if len(patches) != 1 {
+		return errors.New("Expected one patch, got " + strconv.Itoa(len(patches)))
+	}
+	*p = patches[0]
+	return nil
+}
+
+// MarshalJSON encodes a patch as {"start1": number, "start2": number, "length1": number, "length2": number, "diffs": [Diff, ...]}.
+func (p Patch) MarshalJSON() ([]byte, error) {
+	diffs := p.diffs
+	if diffs == nil {
+		diffs = []Diff{}
+	}
+	return json.Marshal(patchJSON{p.Start1, p.Start2, p.Length1, p.Length2, diffs})
+}
+
+// UnmarshalJSON decodes a patch from {"start1": number, "start2": number, "length1": number, "length2": number, "diffs": [Diff, ...]}.
+func (p *Patch) UnmarshalJSON(data []byte) error {
+	var aPatch patchJSON
+	if err := json.Unmarshal(data, &aPatch); err != nil {
+		return err
+	} else if aPatch.Start1 < 0 || aPatch.Start2 < 0 || aPatch.Length1 < 0 || aPatch.Length2 < 0 {
+		return errors.New("Negative position or length in patch: " + string(data))
+	}
+	*p = Patch{aPatch.Diffs, aPatch.Start1, aPatch.Start2, aPatch.Length1, aPatch.Length2}
+	return nil
+}
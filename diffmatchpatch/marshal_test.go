@@ -0,0 +1,154 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperationMarshal(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Operation Operation
+
+		ExpectedText string
+		ExpectedJSON string
+	}
+
+	for i, tc := range []TestCase{
+		{"Delete", DiffDelete, "delete", `"delete"`},
+		{"Equal", DiffEqual, "equal", `"equal"`},
+		{"Insert", DiffInsert, "insert", `"insert"`},
+	} {
+		text, err := tc.Operation.MarshalText()
+		assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedText, string(text), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		data, err := json.Marshal(tc.Operation)
+		assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedJSON, string(data), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		var actual Operation
+		assert.NoError(t, actual.UnmarshalText(text), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Operation, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		actual = 5
+		assert.NoError(t, json.Unmarshal(data, &actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Operation, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		actual = 5
+		assert.NoError(t, json.Unmarshal([]byte(fmt.Sprint(int(tc.Operation))), &actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Operation, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	_, err := Operation(2).MarshalText()
+	assert.EqualError(t, err, "Invalid operation: Operation(2)")
+	_, err = json.Marshal(Operation(2))
+	assert.Error(t, err)
+
+	var op Operation
+	assert.EqualError(t, op.UnmarshalText([]byte("Insert")), "Invalid operation: \"Insert\"")
+	assert.EqualError(t, json.Unmarshal([]byte("2"), &op), "Invalid operation: 2")
+	assert.EqualError(t, json.Unmarshal([]byte("true"), &op), "Invalid operation: true")
+}
+
+func TestDiffMarshal(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Diff Diff
+
+		ExpectedText string
+		ExpectedJSON string
+	}
+
+	for i, tc := range []TestCase{
+		{"Delete", Diff{DiffDelete, "abc"}, "-abc", `{"op":"delete","text":"abc"}`},
+		{"Equal", Diff{DiffEqual, ""}, "=", `{"op":"equal","text":""}`},
+		{"Insert", Diff{DiffInsert, "\u0680 \x00 \t %\n"}, "+\u0680 \x00 \t %\n", "{\"op\":\"insert\",\"text\":\"\u0680 \\u0000 \\t %\\n\"}"},
+	} {
+		text, err := tc.Diff.MarshalText()
+		assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedText, string(text), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		data, err := json.Marshal(tc.Diff)
+		assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedJSON, string(data), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		var actual Diff
+		assert.NoError(t, actual.UnmarshalText(text), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Diff, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		actual = Diff{}
+		assert.NoError(t, json.Unmarshal(data, &actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Diff, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Diffs of other diff-match-patch implementations use numbers.
+	var diffs []Diff
+	assert.NoError(t, json.Unmarshal([]byte(`[{"op":-1,"text":"a"},{"op":1,"text":"b","extra":true}]`), &diffs))
+	assert.Equal(t, []Diff{{DiffDelete, "a"}, {DiffInsert, "b"}}, diffs)
+
+	_, err := Diff{Operation(2), "a"}.MarshalText()
+	assert.EqualError(t, err, "Invalid operation: Operation(2)")
+	_, err = json.Marshal(Diff{Operation(2), "a"})
+	assert.Error(t, err)
+
+	var aDiff Diff
+	assert.EqualError(t, aDiff.UnmarshalText(nil), "Empty diff")
+	assert.EqualError(t, aDiff.UnmarshalText([]byte("*a")), "Invalid diff operation: \"*\"")
+	assert.EqualError(t, json.Unmarshal([]byte(`{"text":"a"}`), &aDiff), `Missing diff operation: {"text":"a"}`)
+	assert.EqualError(t, json.Unmarshal([]byte(`{"op":"replace","text":"a"}`), &aDiff), "Invalid operation: \"replace\"")
+	assert.Error(t, json.Unmarshal([]byte(`["delete","a"]`), &aDiff))
+}
+
+func TestPatchMarshal(t *testing.T) {
+	dmp := New()
+	patches := dmp.PatchMake("The quick brown fox jumps over the lazy dog.", "That quick brown fox jumped over a lazy dog.")
+
+	data, err := json.Marshal(patches)
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"start1":0,"start2":0,"length1":11,"length2":12,"diffs":[{"op":"equal","text":"Th"},{"op":"delete","text":"e"},{"op":"insert","text":"at"},{"op":"equal","text":" quick b"}]},`+
+		`{"start1":21,"start2":21,"length1":18,"length2":17,"diffs":[{"op":"equal","text":"jump"},{"op":"delete","text":"s"},{"op":"insert","text":"ed"},{"op":"equal","text":" over "},{"op":"delete","text":"the"},{"op":"insert","text":"a"},{"op":"equal","text":" laz"}]}]`, string(data))
+	var actual []Patch
+	assert.NoError(t, json.Unmarshal(data, &actual))
+	assert.Equal(t, patches, actual)
+
+	text, err := patches[1].MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, "@@ -22,18 +22,17 @@\n jump\n-s\n+ed\n  over \n-the\n+a\n  laz\n", string(text))
+	var aPatch Patch
+	assert.NoError(t, aPatch.UnmarshalText(text))
+	assert.Equal(t, patches[1], aPatch)
+
+	// Patches survive a round trip through JSON.
+	text1, text2 := speedtestTexts()
+	for _, unit := range []Unit{UnitByte, UnitRune} {
+		dmp.PatchUnit = unit
+		patches := dmp.PatchMake(text1, text2)
+		data, err := json.Marshal(patches)
+		assert.NoError(t, err)
+		var actual []Patch
+		assert.NoError(t, json.Unmarshal(data, &actual))
+		assert.Equal(t, patches, actual)
+		applied, results := dmp.PatchApply(actual, text1)
+		assert.Equal(t, text2, applied)
+		assert.NotContains(t, results, false)
+	}
+
+	data, err = json.Marshal(Patch{})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"start1":0,"start2":0,"length1":0,"length2":0,"diffs":[]}`, string(data))
+
+	assert.EqualError(t, aPatch.UnmarshalText([]byte("")), "Expected one patch, got 0")
+	assert.EqualError(t, aPatch.UnmarshalText(append(text, text...)), "Expected one patch, got 2")
+	assert.EqualError(t, aPatch.UnmarshalText([]byte("Bad\nPatch\n")), "Invalid patch string: Bad")
+	assert.EqualError(t, json.Unmarshal([]byte(`{"start1":-1,"diffs":[]}`), &aPatch), `Negative position or length in patch: {"start1":-1,"diffs":[]}`)
+	assert.EqualError(t, json.Unmarshal([]byte(`{"diffs":[{"op":"move","text":"a"}]}`), &aPatch), "Invalid operation: \"move\"")
+}
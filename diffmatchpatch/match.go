@@ -9,69 +9,236 @@
 package diffmatchpatch
 
 import (
+	"context"
 	"math"
+	"math/bits"
+	"sort"
+	"unicode/utf8"
 )
 
 // MatchMain locates the best instance of 'pattern' in 'text' near 'loc'.
 // Returns -1 if no match found.
 func (dmp *DiffMatchPatch) MatchMain(text, pattern string, loc int) int {
+	loc, _ = dmp.MatchMainContext(context.Background(), text, pattern, loc)
+	return loc
+}
+
+// MatchMainContext locates the best instance of 'pattern' in 'text' near 'loc' and stops searching once ctx is done.
+// Returns -1 if no match found. If the search was cut short, the best match found so far is returned along with ctx.Err().
+func (dmp *DiffMatchPatch) MatchMainContext(ctx context.Context, text, pattern string, loc int) (int, error) {
+	match, err := dmp.MatchBestContext(ctx, text, pattern, loc)
+	return match.Start, err
+}
+
+// MatchBest locates the best instance of 'pattern' in 'text' near 'loc' like MatchMain, but returns the details of the match.
+// Its Start is -1 if no match found.
+func (dmp *DiffMatchPatch) MatchBest(text, pattern string, loc int) Match {
+	match, _ := dmp.MatchBestContext(context.Background(), text, pattern, loc)
+	return match
+}
+
+// MatchBestContext is like MatchBest but stops searching once ctx is done, in which case the best match found so far is returned along with ctx.Err().
+func (dmp *DiffMatchPatch) MatchBestContext(ctx context.Context, text, pattern string, loc int) (Match, error) {
+	match, err := dmp.matchMain(ctx, text, pattern, unitIndex(text, loc, dmp.PatchUnit), dmp.MatchUnit)
+	return dmp.matchToUnit(text, match), err
+}
+
+// matchMain locates the best instance of 'pattern' in 'text' near 'loc' comparing the given unit, with locations counted in bytes.
+func (dmp *DiffMatchPatch) matchMain(ctx context.Context, text, pattern string, loc int, unit Unit) (Match, error) {
 	// Check for null inputs not needed since null can't be passed in C#.
 
 	loc = int(math.Max(0, math.Min(float64(loc), float64(len(text)))))
 	if text == pattern {
 		// Shortcut (potentially not guaranteed by the algorithm)
-		return 0
+		return dmp.matchExact(text, pattern, 0, loc, unit), nil
 	} else if len(text) == 0 {
 		// Nothing to match.
-		return -1
+		return Match{Start: -1, End: -1}, nil
 	} else if loc+len(pattern) <= len(text) && text[loc:loc+len(pattern)] == pattern {
 		// Perfect match at the perfect spot!  (Includes case of null pattern)
-		return loc
+		return dmp.matchExact(text, pattern, loc, loc, unit), nil
 	}
 	// Do a fuzzy compare.
-	return dmp.MatchBitap(text, pattern, loc)
+	return dmp.matchBitap(ctx, text, pattern, loc, unit), ctx.Err()
+}
+
+// matchExact returns the match of 'pattern' as it is at 'start' in 'text', scored relative to 'loc'.
+func (dmp *DiffMatchPatch) matchExact(text, pattern string, start, loc int, unit Unit) Match {
+	distance := unitLen(text[min(start, loc):max(start, loc)], unit)
+	return dmp.matchScored(Match{Start: start, End: start + len(pattern)}, 0, distance, max(1, unitLen(pattern, unit)))
 }
 
 // MatchBitap locates the best instance of 'pattern' in 'text' near 'loc' using the Bitap algorithm.
 // Returns -1 if no match was found.
 func (dmp *DiffMatchPatch) MatchBitap(text, pattern string, loc int) int {
-	// Initialise the alphabet.
-	s := dmp.MatchAlphabet(pattern)
+	match := dmp.matchBitap(context.Background(), text, pattern, unitIndex(text, loc, dmp.PatchUnit), dmp.MatchUnit)
+	return dmp.matchLocToUnit(text, match.Start)
+}
+
+// matchLocToUnit converts a location in text from bytes to PatchUnit.
+func (dmp *DiffMatchPatch) matchLocToUnit(text string, loc int) int {
+	if loc <= 0 {
+		return loc
+	}
+	return unitLen(text[:loc], dmp.PatchUnit)
+}
+
+// matchToUnit converts the location of a match in text from bytes to PatchUnit.
+func (dmp *DiffMatchPatch) matchToUnit(text string, match Match) Match {
+	if match.Start == -1 || dmp.PatchUnit == UnitByte {
+		return match
+	}
+	start, end := match.Start, match.End
+	match.Start = dmp.matchLocToUnit(text, start)
+	match.End = match.Start + unitLen(text[start:end], dmp.PatchUnit)
+	return match
+}
+
+// matchSymbols splits a text into the symbols of the given unit which are compared by the Bitap algorithm, along with the byte index of each symbol.
+// Runes are their own symbols, clusters of several runes get symbols above utf8.MaxRune from ids. If every byte is a symbol the indices are nil.
+func matchSymbols(text string, unit Unit, ids map[string]int) ([]int, []int) {
+	var symbols, indices []int
+	switch unit {
+	case UnitByte:
+		symbols = make([]int, len(text))
+		for i := 0; i < len(text); i++ {
+			symbols[i] = int(text[i])
+		}
+		return symbols, nil
+	case UnitRune:
+		for i := 0; i < len(text); {
+			r, size := utf8.DecodeRuneInString(text[i:])
+			if r == utf8.RuneError && size == 1 {
+				// Invalid UTF-8 never matches a rune.
+				r = -rune(text[i]) - 1
+			}
+			symbols = append(symbols, int(r))
+			indices = append(indices, i)
+			i += size
+		}
+	default:
+		i := 0
+		for _, token := range TokenizeGraphemes(text) {
+			r, size := utf8.DecodeRuneInString(token)
+			if size == len(token) && !(r == utf8.RuneError && size == 1) {
+				symbols = append(symbols, int(r))
+			} else {
+				id, ok := ids[token]
+				if !ok {
+					id = utf8.MaxRune + 1 + len(ids)
+					ids[token] = id
+				}
+				symbols = append(symbols, id)
+			}
+			indices = append(indices, i)
+			i += len(token)
+		}
+	}
+	return symbols, indices
+}
+
+// matchBitap locates the best instance of 'pattern' in 'text' near 'loc' using the Bitap algorithm, comparing the given unit.
+// Locations are counted in bytes, and a match always starts at the start of a symbol. Returns the best match found so far once ctx is done.
+func (dmp *DiffMatchPatch) matchBitap(ctx context.Context, text, pattern string, loc int, unit Unit) Match {
+	ids := map[string]int{}
+	textSymbols, indices := matchSymbols(text, unit, ids)
+	patternSymbols, _ := matchSymbols(pattern, unit, ids)
+
+	// symbolIndex converts a byte index in text to the index of the symbol it points into.
+	symbolIndex := func(i int) (int, bool) {
+		if indices == nil {
+			return i, true
+		}
+		j := sort.SearchInts(indices, i)
+		if j < len(indices) && indices[j] == i {
+			return j, true
+		}
+		return j - 1, false
+	}
+	symbolLoc, _ := symbolIndex(loc)
+	if loc >= len(text) {
+		symbolLoc = len(textSymbols)
+	}
 
 	// Highest score beyond which we give up.
 	scoreThreshold := dmp.MatchThreshold
+	// exactScore returns the score of an exact match at a byte index, if the match consists of whole symbols.
+	exactScore := func(i int) float64 {
+		start, startOk := symbolIndex(i)
+		_, endOk := symbolIndex(i + len(pattern))
+		if !startOk || !endOk && i+len(pattern) < len(text) {
+			return scoreThreshold
+		}
+		return math.Min(dmp.matchBitapScore(0, start, symbolLoc, len(patternSymbols)), scoreThreshold)
+	}
 	// Is there a nearby exact match? (speedup)
-	bestLoc := indexOf(text, pattern, loc)
-	if bestLoc != -1 {
-		scoreThreshold = math.Min(dmp.matchBitapScore(0, bestLoc, loc,
-			pattern), scoreThreshold)
+	if bestLoc := indexOf(text, pattern, loc); bestLoc != -1 {
+		scoreThreshold = exactScore(bestLoc)
 		// What about in the other direction? (speedup)
-		bestLoc = lastIndexOf(text, pattern, loc+len(pattern))
-		if bestLoc != -1 {
-			scoreThreshold = math.Min(dmp.matchBitapScore(0, bestLoc, loc,
-				pattern), scoreThreshold)
+		if bestLoc = lastIndexOf(text, pattern, loc+len(pattern)); bestLoc != -1 {
+			scoreThreshold = exactScore(bestLoc)
 		}
 	}
 
+	var bestLoc, bestErrors int
+	if len(patternSymbols) >= bits.UintSize {
+		// The pattern does not fit into the bits of an int.
+		bestLoc, bestErrors = dmp.matchBitapBlocks(ctx, textSymbols, patternSymbols, symbolLoc, scoreThreshold)
+	} else {
+		bestLoc, bestErrors = dmp.matchBitapInt(ctx, textSymbols, patternSymbols, symbolLoc, scoreThreshold)
+	}
+	if bestLoc == -1 {
+		return Match{Start: -1, End: -1}
+	}
+	match := Match{
+		Start: matchByteIndex(text, indices, bestLoc),
+		End:   matchByteIndex(text, indices, matchEnd(textSymbols, patternSymbols, bestLoc)),
+	}
+	return dmp.matchScored(match, bestErrors, int(math.Abs(float64(symbolLoc-bestLoc))), len(patternSymbols))
+}
+
+// matchByteIndex converts the index of a symbol to a byte index in text, given the byte indices of the symbols.
+func matchByteIndex(text string, indices []int, i int) int {
+	if indices == nil {
+		return min(i, len(text))
+	} else if i >= len(indices) {
+		return len(text)
+	}
+	return indices[i]
+}
+
+// matchScored sets the errors and the score of a match, with e errors at the given distance in symbols from the expected location.
+func (dmp *DiffMatchPatch) matchScored(match Match, e, distance, patternLength int) Match {
+	match.Errors = e
+	match.Accuracy = float64(e) / float64(patternLength)
+	match.Proximity = math.Abs(float64(distance))
+	if dmp.MatchDistance != 0 {
+		match.Proximity /= float64(dmp.MatchDistance)
+	}
+	match.Score = dmp.matchBitapScore(e, distance, 0, patternLength)
+	return match
+}
+
+// matchBitapInt locates the best instance of 'pattern' in 'text' near 'loc' within scoreThreshold, with bit arrays which fit into an int.
+// Returns the location and the errors of the best match, or the best match found so far once ctx is done.
+func (dmp *DiffMatchPatch) matchBitapInt(ctx context.Context, text, pattern []int, loc int, scoreThreshold float64) (int, int) {
+	// Initialise the alphabet.
+	s := matchAlphabetSymbols(pattern)
+
 	// Initialise the bit arrays.
 	matchmask := 1 << uint((len(pattern) - 1))
-	bestLoc = -1
+	bestLoc := -1
+	bestErrors := -1
 
-	var binMin, binMid int
 	binMax := len(pattern) + len(text)
 	lastRd := []int{}
 	for d := 0; d < len(pattern); d++ {
-		// Scan for the best match; each iteration allows for one more error. Run a binary search to determine how far from 'loc' we can stray at this error level.
-		binMin = 0
-		binMid = binMax
-		for binMin < binMid {
-			if dmp.matchBitapScore(d, loc+binMid, loc, pattern) <= scoreThreshold {
-				binMin = binMid
-			} else {
-				binMax = binMid
-			}
-			binMid = (binMax-binMin)/2 + binMin
+		if ctx.Err() != nil {
+			// Give up, the best match so far has to do.
+			break
 		}
+		// Scan for the best match; each iteration allows for one more error.
+		binMid := dmp.matchBitapReach(d, loc, binMax, len(pattern), scoreThreshold)
 		// Use the result from this iteration as the maximum for the next.
 		binMax = binMid
 		start := int(math.Max(1, float64(loc-binMid+1)))
@@ -99,12 +266,13 @@ func (dmp *DiffMatchPatch) MatchBitap(text, pattern string, loc int) int {
 				rd[j] = ((rd[j+1]<<1)|1)&charMatch | (((lastRd[j+1] | lastRd[j]) << 1) | 1) | lastRd[j+1]
 			}
 			if (rd[j] & matchmask) != 0 {
-				score := dmp.matchBitapScore(d, j-1, loc, pattern)
+				score := dmp.matchBitapScore(d, j-1, loc, len(pattern))
 				// This match will almost certainly be better than any existing match.  But check anyway.
 				if score <= scoreThreshold {
 					// Told you so.
 					scoreThreshold = score
 					bestLoc = j - 1
+					bestErrors = d
 					if bestLoc > loc {
 						// When passing loc, don't exceed our current distance from loc.
 						start = int(math.Max(1, float64(2*loc-bestLoc)))
@@ -115,18 +283,127 @@ func (dmp *DiffMatchPatch) MatchBitap(text, pattern string, loc int) int {
 				}
 			}
 		}
-		if dmp.matchBitapScore(d+1, loc, loc, pattern) > scoreThreshold {
+		if dmp.matchBitapScore(d+1, loc, loc, len(pattern)) > scoreThreshold {
 			// No hope for a (better) match at greater error levels.
 			break
 		}
 		lastRd = rd
 	}
-	return bestLoc
+	return bestLoc, bestErrors
+}
+
+// matchBitapBlocks locates the best instance of a long 'pattern' in 'text' near 'loc' within scoreThreshold.
+// The bit arrays are split up into blocks of 64 bits, so that patterns of any length can be matched as a whole.
+func (dmp *DiffMatchPatch) matchBitapBlocks(ctx context.Context, text, pattern []int, loc int, scoreThreshold float64) (int, int) {
+	blocks := (len(pattern) + 63) / 64
+
+	// Initialise the alphabet.
+	s := matchAlphabetBlocks(pattern, blocks)
+
+	// Initialise the bit arrays. The bit arrays of all positions are kept in one slice, block by block.
+	matchBlock := (len(pattern) - 1) / 64
+	matchmask := uint64(1) << uint((len(pattern)-1)%64)
+	bestLoc := -1
+	bestErrors := -1
+
+	binMax := len(pattern) + len(text)
+	lastRd := []uint64{}
+	for d := 0; d < len(pattern); d++ {
+		if ctx.Err() != nil {
+			// Give up, the best match so far has to do.
+			break
+		}
+		// Scan for the best match; each iteration allows for one more error.
+		binMid := dmp.matchBitapReach(d, loc, binMax, len(pattern), scoreThreshold)
+		// Use the result from this iteration as the maximum for the next.
+		binMax = binMid
+		start := int(math.Max(1, float64(loc-binMid+1)))
+		finish := int(math.Min(float64(loc+binMid), float64(len(text))) + float64(len(pattern)))
+
+		rd := make([]uint64, (finish+2)*blocks)
+		// Set the lowest d bits of the last bit array.
+		for i, k := 0, (finish+1)*blocks; i < d; i += 64 {
+			if d-i >= 64 {
+				rd[k] = math.MaxUint64
+			} else {
+				rd[k] = (1 << uint(d-i)) - 1
+			}
+			k++
+		}
+
+		for j := finish; j >= start; j-- {
+			var charMatch []uint64
+			if len(text) > j-1 {
+				charMatch = s[text[j-1]]
+			}
+
+			// Shift the bit arrays of the next position left by one, carrying bits over from block to block.
+			carry := uint64(1)
+			lastCarry := uint64(1)
+			for k := 0; k < blocks; k++ {
+				next := rd[(j+1)*blocks+k]
+				value := next<<1 | carry
+				carry = next >> 63
+				if charMatch == nil {
+					value = 0
+				} else {
+					value &= charMatch[k]
+				}
+				if d != 0 {
+					// Subsequent passes: fuzzy match.
+					lastNext := lastRd[(j+1)*blocks+k]
+					last := lastNext | lastRd[j*blocks+k]
+					value |= last<<1 | lastCarry | lastNext
+					lastCarry = last >> 63
+				}
+				rd[j*blocks+k] = value
+			}
+
+			if (rd[j*blocks+matchBlock] & matchmask) != 0 {
+				score := dmp.matchBitapScore(d, j-1, loc, len(pattern))
+				// This match will almost certainly be better than any existing match.  But check anyway.
+				if score <= scoreThreshold {
+					// Told you so.
+					scoreThreshold = score
+					bestLoc = j - 1
+					bestErrors = d
+					if bestLoc > loc {
+						// When passing loc, don't exceed our current distance from loc.
+						start = int(math.Max(1, float64(2*loc-bestLoc)))
+					} else {
+						// Already passed loc, downhill from here on in.
+						break
+					}
+				}
+			}
+		}
+		if dmp.matchBitapScore(d+1, loc, loc, len(pattern)) > scoreThreshold {
+			// No hope for a (better) match at greater error levels.
+			break
+		}
+		lastRd = rd
+	}
+	return bestLoc, bestErrors
+}
+
+// matchBitapReach runs a binary search to determine how far from 'loc' a match with d errors can stray, without going beyond binMax.
+func (dmp *DiffMatchPatch) matchBitapReach(d, loc, binMax, patternLength int, scoreThreshold float64) int {
+	binMin := 0
+	binMid := binMax
+	for binMin < binMid {
+		if dmp.matchBitapScore(d, loc+binMid, loc, patternLength) <= scoreThreshold {
+			binMin = binMid
+		} else {
+			binMax = binMid
+		}
+		binMid = (binMax-binMin)/2 + binMin
+	}
+	return binMid
 }
 
 // matchBitapScore computes and returns the score for a match with e errors and x location.
-func (dmp *DiffMatchPatch) matchBitapScore(e, x, loc int, pattern string) float64 {
-	accuracy := float64(e) / float64(len(pattern))
+func (dmp *DiffMatchPatch) matchBitapScore(e, x, loc, patternLength int) float64 {
+	accuracy := float64(e) / float64(patternLength)
 	proximity := math.Abs(float64(loc - x))
 	if dmp.MatchDistance == 0 {
 		// Dodge divide by zero error.
@@ -158,3 +435,231 @@ func (dmp *DiffMatchPatch) MatchAlphabet(pattern string) map[byte]int {
 	}
 	return s
 }
+
+// matchAlphabetSymbols initialises the alphabet of symbols for the Bitap algorithm.
+func matchAlphabetSymbols(pattern []int) map[int]int {
+	s := map[int]int{}
+	for i, c := range pattern {
+		s[c] |= 1 << uint(len(pattern)-i-1)
+	}
+	return s
+}
+
+// matchAlphabetBlocks initialises the alphabet of symbols for the Bitap algorithm with bit arrays of the given number of 64 bit blocks.
+func matchAlphabetBlocks(pattern []int, blocks int) map[int][]uint64 {
+	s := map[int][]uint64{}
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		if _, ok := s[c]; !ok {
+			s[c] = make([]uint64, blocks)
+		}
+		bit := len(pattern) - i - 1
+		s[c][bit/64] |= 1 << uint(bit%64)
+	}
+	return s
+}
+
+// Match represents one fuzzy match of a pattern in a text.
+type Match struct {
+	// Start and End locate the matched text, counted in PatchUnit.
+	Start int
+	End   int
+	// Errors is the number of symbols of the pattern which were inserted, deleted or substituted to match, counted in MatchUnit.
+	Errors int
+	// Accuracy is the share of errors in the length of the pattern.
+	Accuracy float64
+	// Proximity is the distance from the expected location in symbols, relative to MatchDistance.
+	Proximity float64
+	// Score weighs the errors and the distance from the expected location like MatchMain does, 0 is a perfect match at the expected location.
+	// It is the sum of Accuracy and Proximity, except that any match away from the expected location scores 1 if MatchDistance is 0.
+	Score float64
+}
+
+// MatchAll locates every instance of 'pattern' in 'text' which is scored within MatchThreshold relative to 'loc'.
+// Matches which overlap a better scoring match are left out. The matches are ordered by their position, or by their score if byScore is true.
+func (dmp *DiffMatchPatch) MatchAll(text, pattern string, loc int, byScore bool) []Match {
+	matches, _ := dmp.MatchAllContext(context.Background(), text, pattern, loc, byScore)
+	return matches
+}
+
+// MatchAllContext is like MatchAll but stops searching once ctx is done, in which case the matches found so far are returned along with ctx.Err().
+func (dmp *DiffMatchPatch) MatchAllContext(ctx context.Context, text, pattern string, loc int, byScore bool) ([]Match, error) {
+	matches := dmp.matchAll(ctx, text, pattern, unitIndex(text, loc, dmp.PatchUnit), dmp.MatchUnit)
+	for i := range matches {
+		matches[i] = dmp.matchToUnit(text, matches[i])
+	}
+	if !byScore {
+		sort.Slice(matches, func(i, j int) bool {
+			return matches[i].Start < matches[j].Start
+		})
+	}
+	return matches, ctx.Err()
+}
+
+// matchAll locates every instance of 'pattern' in 'text' which is scored within MatchThreshold relative to 'loc', comparing the given unit.
+// Locations are counted in bytes. The matches are ordered by their score, overlapping matches with a worse score are left out.
+func (dmp *DiffMatchPatch) matchAll(ctx context.Context, text, pattern string, loc int, unit Unit) []Match {
+	if len(pattern) == 0 || len(text) == 0 {
+		return []Match{}
+	}
+
+	ids := map[string]int{}
+	textSymbols, indices := matchSymbols(text, unit, ids)
+	patternSymbols, _ := matchSymbols(pattern, unit, ids)
+	symbolLoc := len(textSymbols)
+	if indices == nil {
+		symbolLoc = min(loc, len(text))
+	} else if loc < len(text) {
+		symbolLoc = sort.SearchInts(indices, loc+1) - 1
+	}
+
+	// Even a match at the expected location may not need more errors than these.
+	maxErrors := min(len(patternSymbols)-1, int(dmp.MatchThreshold*float64(len(patternSymbols))))
+	if maxErrors < 0 {
+		return []Match{}
+	}
+
+	var candidates []Match
+	for start, errors := range dmp.matchBitapErrors(ctx, textSymbols, patternSymbols, maxErrors) {
+		if errors == -1 {
+			continue
+		}
+		candidate := dmp.matchScored(Match{Start: start}, errors, start-symbolLoc, len(patternSymbols))
+		if candidate.Score > dmp.MatchThreshold {
+			continue
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	// Try the best matches first, so that they suppress the worse ones they overlap with.
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score < candidates[j].Score
+	})
+	// taken marks the symbols which are part of a match already.
+	taken := make([]bool, len(textSymbols))
+	matches := []Match{}
+	for _, candidate := range candidates {
+		if taken[candidate.Start] {
+			continue
+		}
+		end := matchEnd(textSymbols, patternSymbols, candidate.Start)
+		overlaps := false
+		for i := candidate.Start; i < end; i++ {
+			if taken[i] {
+				overlaps = true
+				break
+			}
+		}
+		if overlaps {
+			continue
+		}
+		for i := candidate.Start; i < end; i++ {
+			taken[i] = true
+		}
+
+		candidate.Start, candidate.End = matchByteIndex(text, indices, candidate.Start), matchByteIndex(text, indices, end)
+		matches = append(matches, candidate)
+	}
+	return matches
+}
+
+// matchBitapErrors runs the Bitap algorithm across the whole text and returns the fewest errors with which 'pattern' matches at each position of 'text'.
+// Positions without a match of at most maxErrors errors are -1. Positions not scanned once ctx is done are -1 as well.
+func (dmp *DiffMatchPatch) matchBitapErrors(ctx context.Context, text, pattern []int, maxErrors int) []int {
+	blocks := (len(pattern) + 63) / 64
+
+	// Initialise the alphabet.
+	s := matchAlphabetBlocks(pattern, blocks)
+
+	errors := make([]int, len(text))
+	for i := range errors {
+		errors[i] = -1
+	}
+
+	// Initialise the bit arrays. The bit arrays of all positions are kept in one slice, block by block.
+	matchBlock := (len(pattern) - 1) / 64
+	matchmask := uint64(1) << uint((len(pattern)-1)%64)
+	finish := len(text) + len(pattern)
+
+	lastRd := []uint64{}
+	for d := 0; d <= maxErrors; d++ {
+		if ctx.Err() != nil {
+			break
+		}
+		rd := make([]uint64, (finish+2)*blocks)
+		// Set the lowest d bits of the last bit array.
+		for i, k := 0, (finish+1)*blocks; i < d; i += 64 {
+			if d-i >= 64 {
+				rd[k] = math.MaxUint64
+			} else {
+				rd[k] = (1 << uint(d-i)) - 1
+			}
+			k++
+		}
+
+		for j := finish; j >= 1; j-- {
+			var charMatch []uint64
+			if len(text) > j-1 {
+				charMatch = s[text[j-1]]
+			}
+
+			// Shift the bit arrays of the next position left by one, carrying bits over from block to block.
+			carry := uint64(1)
+			lastCarry := uint64(1)
+			for k := 0; k < blocks; k++ {
+				next := rd[(j+1)*blocks+k]
+				value := next<<1 | carry
+				carry = next >> 63
+				if charMatch == nil {
+					value = 0
+				} else {
+					value &= charMatch[k]
+				}
+				if d != 0 {
+					// Subsequent passes: fuzzy match.
+					lastNext := lastRd[(j+1)*blocks+k]
+					last := lastNext | lastRd[j*blocks+k]
+					value |= last<<1 | lastCarry | lastNext
+					lastCarry = last >> 63
+				}
+				rd[j*blocks+k] = value
+			}
+
+			if j-1 < len(text) && errors[j-1] == -1 && (rd[j*blocks+matchBlock]&matchmask) != 0 {
+				errors[j-1] = d
+			}
+		}
+		lastRd = rd
+	}
+	return errors
+}
+
+// matchEnd returns the end of the match of 'pattern' which starts at 'start' in 'text' with the fewest errors.
+// Of several equally good ends the one closest to the length of the pattern is chosen.
+func matchEnd(text, pattern []int, start int) int {
+	// The edit distance between the pattern so far and the text from start up to each end, one row per symbol of the pattern.
+	limit := min(len(text)-start, 2*len(pattern))
+	row := make([]int, limit+1)
+	for k := range row {
+		row[k] = k
+	}
+	for i := 1; i <= len(pattern); i++ {
+		diagonal := row[0]
+		row[0] = i
+		for k := 1; k <= limit; k++ {
+			cost := 1
+			if text[start+k-1] == pattern[i-1] {
+				cost = 0
+			}
+			diagonal, row[k] = row[k], min(diagonal+cost, min(row[k], row[k-1])+1)
+		}
+	}
+
+	best := 0
+	for k := 1; k <= limit; k++ {
+		if row[k] < row[best] || row[k] == row[best] && math.Abs(float64(k-len(pattern))) < math.Abs(float64(best-len(pattern))) {
+			best = k
+		}
+	}
+	return start + best
+}
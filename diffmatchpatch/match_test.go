@@ -9,7 +9,10 @@
 package diffmatchpatch
 
 import (
+	"context"
 	"fmt"
+	"math/rand"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -138,6 +141,136 @@ func TestMatchBitap(t *testing.T) {
 	}
 }
 
+func TestMatchBitapBlocks(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text     string
+		Pattern  string
+		Location int
+
+		Expected int
+	}
+
+	dmp := New()
+	dmp.MatchDistance = 1000
+	dmp.MatchThreshold = 0.5
+
+	random := rand.New(rand.NewSource(1))
+	randomText := func(n int) string {
+		var text []byte
+		for i := 0; i < n; i++ {
+			text = append(text, "abcdefgh "[random.Intn(9)])
+		}
+		return string(text)
+	}
+	mutate := func(text string, n int) string {
+		data := []byte(text)
+		for i := 0; i < n; i++ {
+			data[random.Intn(len(data))] = 'x'
+		}
+		return string(data)
+	}
+
+	text := randomText(3000)
+	pattern := text[1200:1500]
+
+	for i, tc := range []TestCase{
+		{"Exact match", text, pattern, 1200, 1200},
+		{"Exact match away from location", text, pattern, 1100, 1200},
+		{"Fuzzy match", text, mutate(pattern, 30), 1150, 1200},
+		{"Fuzzy match with insertions", text[:1300] + strings.Repeat("y", 20) + text[1300:], pattern, 1200, 1200},
+		{"Fuzzy match with deletions", text[:1300] + text[1320:], pattern, 1200, 1200},
+		{"No match", text, randomText(300), 1200, -1},
+		{"Oversized pattern", pattern, "x" + pattern + "y", 0, 0},
+	} {
+		actual := dmp.MatchBitap(tc.Text, tc.Pattern, tc.Location)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Short patterns are matched the same way in blocks as in an int.
+	for i := 0; i < 500; i++ {
+		text := randomText(random.Intn(200) + 1)
+		pattern := mutate(randomText(random.Intn(40)+1), random.Intn(4))
+		if i%2 == 0 {
+			start := random.Intn(len(text))
+			pattern = mutate(text[start:min(len(text), start+random.Intn(40)+1)], random.Intn(4))
+		}
+		loc := random.Intn(len(text))
+
+		textSymbols, _ := matchSymbols(text, UnitByte, nil)
+		patternSymbols, _ := matchSymbols(pattern, UnitByte, nil)
+		expected, expectedErrors := dmp.matchBitapInt(context.Background(), textSymbols, patternSymbols, loc, dmp.MatchThreshold)
+		actual, actualErrors := dmp.matchBitapBlocks(context.Background(), textSymbols, patternSymbols, loc, dmp.MatchThreshold)
+		assert.Equal(t, expected, actual, fmt.Sprintf("Random case #%d", i))
+		assert.Equal(t, expectedErrors, actualErrors, fmt.Sprintf("Random case #%d", i))
+	}
+}
+
+func TestMatchUnit(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text      string
+		Pattern   string
+		Location  int
+		MatchUnit Unit
+		PatchUnit Unit
+
+		Expected int
+	}
+
+	dmp := New()
+	dmp.MatchDistance = 100
+	dmp.MatchThreshold = 0.3
+
+	for i, tc := range []TestCase{
+		{"ASCII in bytes", "abcdefghijk", "efxhi", 0, UnitByte, UnitByte, 4},
+		{"ASCII in runes", "abcdefghijk", "efxhi", 0, UnitRune, UnitByte, 4},
+		{"ASCII in graphemes", "abcdefghijk", "efxhi", 0, UnitGrapheme, UnitByte, 4},
+		{"Byte matches start within runes", "l'\u00e9cole", "ecole", 0, UnitByte, UnitByte, 3},
+		{"Rune matches start at runes", "l'\u00e9cole", "ecole", 0, UnitRune, UnitByte, 2},
+		{"Locations in bytes", "\u65e5\u672c\u8a9e\u306e\u6587\u7ae0", "\u672c\u8a9e\u304c\u6587", 0, UnitRune, UnitByte, 3},
+		{"Locations in runes", "\u65e5\u672c\u8a9e\u306e\u6587\u7ae0", "\u672c\u8a9e\u304c\u6587", 0, UnitRune, UnitRune, 1},
+		{"Exact match in runes", "\u65e5\u672c\u8a9e\u306e\u6587\u7ae0", "\u306e\u6587", 3, UnitByte, UnitRune, 3},
+		{"Emoji in runes", "\U0001f98a \U0001f436 \U0001f431 \U0001f98a", "\U0001f436 \U0001f42d \U0001f98a", 0, UnitRune, UnitRune, 2},
+		{"Combining marks in runes", "un cafe\u0301 noir", "cafe\u0300 n", 0, UnitRune, UnitRune, 3},
+		{"Emoji sequences in runes", "x\U0001f469\u200d\U0001f4bby", "x\U0001f469\u200d\U0001f52cy", 0, UnitRune, UnitRune, 0},
+		{"Emoji sequences in graphemes", "x\U0001f469\u200d\U0001f4bby", "x\U0001f469\u200d\U0001f52cy", 0, UnitGrapheme, UnitRune, -1},
+		{"Flags in graphemes", "ab\U0001f1ef\U0001f1f5cd", "ab\U0001f1eb\U0001f1f7cd", 0, UnitGrapheme, UnitRune, 0},
+		{"Invalid UTF-8 in runes", "ab\xffcd\xfe", "bxcd", 0, UnitRune, UnitByte, 1},
+	} {
+		dmp.MatchUnit = tc.MatchUnit
+		dmp.PatchUnit = tc.PatchUnit
+
+		actual := dmp.MatchMain(tc.Text, tc.Pattern, tc.Location)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Fuzzy matches in runes never start within a rune.
+	dmp.MatchUnit = UnitRune
+	dmp.PatchUnit = UnitByte
+	dmp.MatchThreshold = 0.5
+	random := rand.New(rand.NewSource(1))
+	corpus := []rune("\u65e5\u672c\u8a9e\u306e\u6587\u7ae0 abc \U0001f98a\u00e9")
+	randomText := func(n int) string {
+		var text []rune
+		for i := 0; i < n; i++ {
+			text = append(text, corpus[random.Intn(len(corpus))])
+		}
+		return string(text)
+	}
+	for i := 0; i < 200; i++ {
+		text := randomText(random.Intn(100) + 1)
+		pattern := randomText(random.Intn(10) + 1)
+
+		actual := dmp.MatchMain(text, pattern, random.Intn(len(text)))
+		if actual != -1 {
+			assert.Equal(t, actual, runeBoundaryBefore(text, actual), fmt.Sprintf("Random case #%d", i))
+		}
+	}
+}
+
 func TestMatchMain(t *testing.T) {
 	type TestCase struct {
 		Name string
@@ -172,3 +305,147 @@ func TestMatchMain(t *testing.T) {
 		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %#v", i, tc))
 	}
 }
+
+func TestMatchMainContext(t *testing.T) {
+	dmp := New()
+
+	actual, err := dmp.MatchMainContext(context.Background(), "abcdef", "defy", 4)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, actual)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Shortcuts do not need to search.
+	actual, err = dmp.MatchMainContext(ctx, "abcdef", "de", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, actual)
+
+	// No fuzzy search takes place once ctx is done.
+	actual, err = dmp.MatchMainContext(ctx, "abcdef", "defy", 4)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, -1, actual)
+}
+
+func TestMatchAll(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text     string
+		Pattern  string
+		Location int
+		ByScore  bool
+
+		Expected []Match
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Null text", "", "abc", 0, false, []Match{}},
+		{"Null pattern", "abc", "", 0, false, []Match{}},
+		{"No match", "abcdefghij", "xyz", 0, false, []Match{}},
+		{"Exact matches", "abcXdefXabcdef", "abc", 0, false, []Match{{Start: 0, End: 3, Errors: 0, Score: 0}, {Start: 8, End: 11, Errors: 0, Score: 0.008}}},
+		{"Ordered by position", "abcXabc", "abc", 4, false, []Match{{Start: 0, End: 3, Errors: 0, Score: 0.004}, {Start: 4, End: 7, Errors: 0, Score: 0}}},
+		{"Ordered by score", "abcXabc", "abc", 4, true, []Match{{Start: 4, End: 7, Errors: 0, Score: 0}, {Start: 0, End: 3, Errors: 0, Score: 0.004}}},
+		{"Overlapping matches", "aaaa", "aa", 0, false, []Match{{Start: 0, End: 2, Errors: 0, Score: 0}, {Start: 2, End: 4, Errors: 0, Score: 0.002}}},
+		{"Fuzzy matches", "xx abXcd yy abd", "abcd", 0, false, []Match{{Start: 3, End: 8, Errors: 1, Score: 0.253}, {Start: 12, End: 15, Errors: 1, Score: 0.262}}},
+		{"Multibyte text", "日本語の文章と日本の本", "日本語", 0, false, []Match{{Start: 0, End: 9, Errors: 0, Score: 0}, {Start: 21, End: 30, Errors: 3, Score: 0.021 + 3.0/9}}},
+	} {
+		actual := dmp.MatchAll(tc.Text, tc.Pattern, tc.Location, tc.ByScore)
+		assert.Equal(t, len(tc.Expected), len(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		for j := range tc.Expected {
+			if j < len(actual) {
+				assert.Equal(t, tc.Expected[j].Start, actual[j].Start, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+				assert.Equal(t, tc.Expected[j].End, actual[j].End, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+				assert.Equal(t, tc.Expected[j].Errors, actual[j].Errors, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+				assert.InDelta(t, tc.Expected[j].Score, actual[j].Score, 1e-9, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+			}
+		}
+	}
+
+	// Locations count in PatchUnit and errors in MatchUnit.
+	dmp.PatchUnit = UnitRune
+	dmp.MatchUnit = UnitRune
+	actual := dmp.MatchAll("日本語の文章と日本の本", "日本語", 0, false)
+	assert.Equal(t, []Match{{Start: 0, End: 3}, {Start: 7, End: 10, Errors: 1, Accuracy: 1.0 / 3, Proximity: 0.007, Score: 1.0/3 + 0.007}}, actual)
+
+	// Matches which are too far away from the location are left out.
+	dmp.MatchDistance = 10
+	actual = dmp.MatchAll("abc"+strings.Repeat(".", 20)+"abc", "abc", 0, false)
+	assert.Equal(t, []Match{{Start: 0, End: 3}}, actual)
+
+	dmp = New()
+	random := rand.New(rand.NewSource(1))
+	randomText := func(n int) string {
+		var text []byte
+		for i := 0; i < n; i++ {
+			text = append(text, "abcd "[random.Intn(5)])
+		}
+		return string(text)
+	}
+	for i := 0; i < 300; i++ {
+		text := randomText(random.Intn(300) + 1)
+		pattern := randomText(random.Intn(70) + 1)
+		loc := random.Intn(len(text))
+
+		// There are matches whenever MatchBitap finds the best one, which is among them.
+		matches := dmp.MatchAll(text, pattern, loc, true)
+		best := dmp.MatchBitap(text, pattern, loc)
+		if best == -1 {
+			assert.Empty(t, matches, fmt.Sprintf("Random case #%d", i))
+			continue
+		} else if !assert.NotEmpty(t, matches, fmt.Sprintf("Random case #%d", i)) {
+			continue
+		}
+		assert.Equal(t, dmp.matchBitapScore(matches[0].Errors, best, loc, len(pattern)), matches[0].Score, fmt.Sprintf("Random case #%d", i))
+
+		covered := make([]bool, len(text))
+		for _, match := range matches {
+			assert.True(t, match.Score <= dmp.MatchThreshold, fmt.Sprintf("Random case #%d", i))
+			assert.Equal(t, dmp.matchBitapScore(match.Errors, match.Start, loc, len(pattern)), match.Score, fmt.Sprintf("Random case #%d", i))
+			// The errors are no more than a diff takes to turn the pattern into the matched text.
+			assert.True(t, match.Errors <= dmp.DiffLevenshtein(dmp.DiffMain(pattern, text[match.Start:match.End], false)), fmt.Sprintf("Random case #%d", i))
+			for j := match.Start; j < match.End; j++ {
+				assert.False(t, covered[j], fmt.Sprintf("Random case #%d", i))
+				covered[j] = true
+			}
+		}
+	}
+}
+
+func TestMatchBest(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text     string
+		Pattern  string
+		Location int
+
+		Expected Match
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Null text", "", "abc", 0, Match{Start: -1, End: -1}},
+		{"No match", "abcdef", "xyz", 0, Match{Start: -1, End: -1}},
+		{"Equality", "abcdef", "abcdef", 1000, Match{Start: 0, End: 6, Proximity: 0.006, Score: 0.006}},
+		{"Exact match", "abcdef", "de", 3, Match{Start: 3, End: 5}},
+		{"Beyond end match", "abcdef", "defy", 4, Match{Start: 3, End: 6, Errors: 1, Accuracy: 0.25, Proximity: 0.001, Score: 0.251}},
+		{"Insertion", "The quick brown fox", "quack brown", 2, Match{Start: 4, End: 15, Errors: 1, Accuracy: 1.0 / 11, Proximity: 0.002, Score: 1.0/11 + 0.002}},
+		{"Substitutions", "The quick brown fox", "quick brownie", 4, Match{Start: 4, End: 17, Errors: 2, Accuracy: 2.0 / 13, Score: 2.0 / 13}},
+	} {
+		actual := dmp.MatchBest(tc.Text, tc.Pattern, tc.Location)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Expected.Start, dmp.MatchMain(tc.Text, tc.Pattern, tc.Location), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Locations count in PatchUnit and errors in MatchUnit.
+	dmp.PatchUnit = UnitRune
+	actual := dmp.MatchBest("日本語の文章です", "文草で", 2)
+	assert.Equal(t, Match{Start: 4, End: 7, Errors: 3, Accuracy: 3.0 / 9, Proximity: 0.006, Score: 3.0/9 + 0.006}, actual)
+	dmp.MatchUnit = UnitRune
+	actual = dmp.MatchBest("日本語の文章です", "文草で", 2)
+	assert.Equal(t, Match{Start: 4, End: 7, Errors: 1, Accuracy: 1.0 / 3, Proximity: 0.002, Score: 1.0/3 + 0.002}, actual)
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+// MergeRegion represents one region of a three-way merge.
+type MergeRegion struct {
+	// Conflict is true if ours and theirs changed the region in different ways.
+	Conflict bool
+	// Text is the merged text of a region without conflict.
+	Text string
+	// Base, Ours and Theirs hold the three versions of a conflicting region.
+	Base   string
+	Ours   string
+	Theirs string
+}
+
+// mergeHunk is a change of one side, given as a range of base lines which is replaced by a range of lines of that side.
+type mergeHunk struct {
+	base1 int
+	base2 int
+	side1 int
+	side2 int
+}
+
+// Merge performs a line-based three-way merge of two texts which were both derived from base.
+// Changes of ours and theirs which neither overlap nor touch are merged automatically, as are identical changes. Every other change results in a conflicting region.
+func (dmp *DiffMatchPatch) Merge(base, ours, theirs string) []MergeRegion {
+	// Hash the lines of all three texts into one shared line array.
+	lineArray := []string{""}
+	lineHash := map[string]int{}
+	baseRunes := dmp.diffLinesToRunesMunge(base, &lineArray, lineHash)
+	oursRunes := dmp.diffLinesToRunesMunge(ours, &lineArray, lineHash)
+	theirsRunes := dmp.diffLinesToRunesMunge(theirs, &lineArray, lineHash)
+
+	lines := func(runes []rune, start, end int) string {
+		var text bytes.Buffer
+		for _, r := range runes[start:end] {
+			_, _ = text.WriteString(lineArray[r])
+		}
+		return text.String()
+	}
+
+	oursHunks := mergeHunks(dmp.DiffMainRunes(baseRunes, oursRunes, false))
+	theirsHunks := mergeHunks(dmp.DiffMainRunes(baseRunes, theirsRunes, false))
+
+	regions := []MergeRegion{}
+	addRegion := func(region MergeRegion) {
+		if !region.Conflict {
+			if len(region.Text) == 0 {
+				return
+			}
+			if n := len(regions); n != 0 && !regions[n-1].Conflict {
+				// Join consecutive regions without conflict.
+				regions[n-1].Text += region.Text
+				return
+			}
+		}
+		regions = append(regions, region)
+	}
+
+	basePointer := 0
+	o, t := 0, 0
+	for o < len(oursHunks) || t < len(theirsHunks) {
+		// Start a chunk with whichever hunk comes first in base.
+		var lo, hi int
+		if t == len(theirsHunks) || (o < len(oursHunks) && oursHunks[o].base1 <= theirsHunks[t].base1) {
+			lo, hi = oursHunks[o].base1, oursHunks[o].base2
+		} else {
+			lo, hi = theirsHunks[t].base1, theirsHunks[t].base2
+		}
+		// Absorb every hunk of either side which overlaps or touches the chunk.
+		o2, t2 := o, t
+		for {
+			if o2 < len(oursHunks) && oursHunks[o2].base1 <= hi {
+				hi = max(hi, oursHunks[o2].base2)
+				o2++
+			} else if t2 < len(theirsHunks) && theirsHunks[t2].base1 <= hi {
+				hi = max(hi, theirsHunks[t2].base2)
+				t2++
+			} else {
+				break
+			}
+		}
+
+		// The lines before the chunk are unchanged on both sides.
+		addRegion(MergeRegion{Text: lines(baseRunes, basePointer, lo)})
+
+		baseText := lines(baseRunes, lo, hi)
+		oursText := baseText
+		if o2 > o {
+			start, end := mergeHunksRange(oursHunks[o:o2], lo, hi)
+			oursText = lines(oursRunes, start, end)
+		}
+		theirsText := baseText
+		if t2 > t {
+			start, end := mergeHunksRange(theirsHunks[t:t2], lo, hi)
+			theirsText = lines(theirsRunes, start, end)
+		}
+
+		if o2 == o {
+			addRegion(MergeRegion{Text: theirsText})
+		} else if t2 == t || oursText == theirsText {
+			addRegion(MergeRegion{Text: oursText})
+		} else {
+			addRegion(MergeRegion{
+				Conflict: true,
+				Base:     baseText,
+				Ours:     oursText,
+				Theirs:   theirsText,
+			})
+		}
+
+		basePointer = hi
+		o, t = o2, t2
+	}
+	addRegion(MergeRegion{Text: lines(baseRunes, basePointer, len(baseRunes))})
+
+	return regions
+}
+
+// mergeHunks converts a diff of line hashes into the hunks it consists of.
+func mergeHunks(diffs []Diff) []mergeHunk {
+	hunks := []mergeHunk{}
+	basePointer := 0
+	sidePointer := 0
+	for _, aDiff := range diffs {
+		// Every rune represents one line.
+		n := utf8.RuneCountInString(aDiff.Text)
+		if aDiff.Type == DiffEqual {
+			basePointer += n
+			sidePointer += n
+			continue
+		}
+
+		if last := len(hunks) - 1; last < 0 || hunks[last].base2 != basePointer || hunks[last].side2 != sidePointer {
+			hunks = append(hunks, mergeHunk{basePointer, basePointer, sidePointer, sidePointer})
+		}
+		if aDiff.Type == DiffDelete {
+			basePointer += n
+			hunks[len(hunks)-1].base2 = basePointer
+		} else {
+			sidePointer += n
+			hunks[len(hunks)-1].side2 = sidePointer
+		}
+	}
+
+	return hunks
+}
+
+// mergeHunksRange returns the range of side lines which corresponds to the base lines lo to hi, given all hunks of the side within that range.
+func mergeHunksRange(hunks []mergeHunk, lo, hi int) (int, int) {
+	// Outside of the hunks base and side lines correspond one to one.
+	first := hunks[0]
+	last := hunks[len(hunks)-1]
+	return first.side1 - (first.base1 - lo), last.side2 + (hi - last.base2)
+}
+
+// MergeToText converts the regions of a three-way merge into text. Conflicts are marked the way "git merge" does with the "diff3" conflict style, using the given labels after the markers.
+// Returns the text as well as whether any conflicts are contained.
+func (dmp *DiffMatchPatch) MergeToText(regions []MergeRegion, oursLabel, baseLabel, theirsLabel string) (string, bool) {
+	var text bytes.Buffer
+	conflicts := false
+
+	marker := func(m string, label string) {
+		_, _ = text.WriteString(m)
+		if len(label) != 0 {
+			_, _ = text.WriteString(" " + label)
+		}
+		_, _ = text.WriteString("\n")
+	}
+	section := func(s string) {
+		_, _ = text.WriteString(s)
+		if len(s) != 0 && !strings.HasSuffix(s, "\n") {
+			// Keep the next marker on its own line.
+			_, _ = text.WriteString("\n")
+		}
+	}
+
+	for _, region := range regions {
+		if !region.Conflict {
+			_, _ = text.WriteString(region.Text)
+			continue
+		}
+
+		conflicts = true
+		marker("<<<<<<<", oursLabel)
+		section(region.Ours)
+		marker("|||||||", baseLabel)
+		section(region.Base)
+		marker("=======", "")
+		section(region.Theirs)
+		marker(">>>>>>>", theirsLabel)
+	}
+
+	return text.String(), conflicts
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Base   string
+		Ours   string
+		Theirs string
+
+		Expected []MergeRegion
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Null case", "", "", "", []MergeRegion{}},
+		{"Unchanged", "a\nb\n", "a\nb\n", "a\nb\n", []MergeRegion{{Text: "a\nb\n"}}},
+		{"Separate changes", "a\nb\nc\nd\ne\n", "A\nb\nc\nd\ne\n", "a\nb\nc\nd\nE\n", []MergeRegion{{Text: "A\nb\nc\nd\nE\n"}}},
+		{"Only theirs", "a\nb\nc\n", "a\nb\nc\n", "a\nc\n", []MergeRegion{{Text: "a\nc\n"}}},
+		{"Identical changes", "a\nb\nc\n", "a\nX\nc\n", "a\nX\nc\n", []MergeRegion{{Text: "a\nX\nc\n"}}},
+		{"Conflict", "a\nb\nc\n", "a\nX\nc\n", "a\nY\nc\n", []MergeRegion{
+			{Text: "a\n"},
+			{Conflict: true, Base: "b\n", Ours: "X\n", Theirs: "Y\n"},
+			{Text: "c\n"},
+		}},
+		{"Adjacent changes", "a\nb\nc\nd\n", "a\nB\nc\nd\n", "a\nb\nC\nd\n", []MergeRegion{
+			{Text: "a\n"},
+			{Conflict: true, Base: "b\nc\n", Ours: "B\nc\n", Theirs: "b\nC\n"},
+			{Text: "d\n"},
+		}},
+		{"Insertions at the same place", "a\nb\n", "a\nb\nours\n", "a\nb\ntheirs\n", []MergeRegion{
+			{Text: "a\nb\n"},
+			{Conflict: true, Base: "", Ours: "ours\n", Theirs: "theirs\n"},
+		}},
+	} {
+		actual := dmp.Merge(tc.Base, tc.Ours, tc.Theirs)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestMergeToText(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Base   string
+		Ours   string
+		Theirs string
+
+		Expected          string
+		ExpectedConflicts bool
+	}
+
+	dmp := New()
+
+	// Expected outputs were produced by "git merge-file --diff3 -p -L ours -L base -L theirs".
+	for i, tc := range []TestCase{
+		{"Separate changes", "a\nb\nc\nd\ne\n", "A\nb\nc\nd\ne\n", "a\nb\nc\nd\nE\n", "A\nb\nc\nd\nE\n", false},
+		{"Conflict", "a\nb\nc\n", "a\nX\nc\n", "a\nY\nc\n", "a\n<<<<<<< ours\nX\n||||||| base\nb\n=======\nY\n>>>>>>> theirs\nc\n", true},
+		{"Adjacent changes", "a\nb\nc\nd\n", "a\nB\nc\nd\n", "a\nb\nC\nd\n", "a\n<<<<<<< ours\nB\nc\n||||||| base\nb\nc\n=======\nb\nC\n>>>>>>> theirs\nd\n", true},
+		{"Insertions at the same place", "a\nb\n", "a\nb\nours\n", "a\nb\ntheirs\n", "a\nb\n<<<<<<< ours\nours\n||||||| base\n=======\ntheirs\n>>>>>>> theirs\n", true},
+		{"Missing newline", "a", "b", "c", "<<<<<<< ours\nb\n||||||| base\na\n=======\nc\n>>>>>>> theirs\n", true},
+	} {
+		actual, actualConflicts := dmp.MergeToText(dmp.Merge(tc.Base, tc.Ours, tc.Theirs), "ours", "base", "theirs")
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedConflicts, actualConflicts, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
@@ -10,15 +10,18 @@ package diffmatchpatch
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"math"
 	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 // Patch represents one patch operation.
+// Its positions and lengths count bytes or runes, depending on the PatchUnit of the DiffMatchPatch which made it.
 type Patch struct {
 	diffs   []Diff
 	Start1  int
@@ -70,33 +73,45 @@ func (p *Patch) String() string {
 	return unescaper.Replace(text.String())
 }
 
-// PatchAddContext increases the context until it is unique, but doesn't let the pattern expand beyond MatchMaxBits.
+// PatchAddContext increases the context until it is unique, but doesn't let the pattern expand beyond MatchMaxBits unless it is 0.
 func (dmp *DiffMatchPatch) PatchAddContext(patch Patch, text string) Patch {
 	if len(text) == 0 {
 		return patch
 	}
 
+	patches := dmp.patchesToUnit([]Patch{patch}, text, dmp.PatchUnit, UnitByte)
+	patches[0] = dmp.patchAddContext(patches[0], text)
+
+	return dmp.patchesToUnit(patches, text, UnitByte, dmp.PatchUnit)[0]
+}
+
+// patchAddContext increases the context of a patch which counts bytes until it is unique, without splitting a rune.
+func (dmp *DiffMatchPatch) patchAddContext(patch Patch, text string) Patch {
+	if len(text) == 0 {
+		return patch
+	}
+
 	pattern := text[patch.Start2 : patch.Start2+patch.Length1]
 	padding := 0
 
 	// Look for the first and last matches of pattern in text.  If two different matches are found, increase the pattern length.
 	for strings.Index(text, pattern) != strings.LastIndex(text, pattern) &&
-		len(pattern) < dmp.MatchMaxBits-2*dmp.PatchMargin {
+		(dmp.MatchMaxBits == 0 || len(pattern) < dmp.MatchMaxBits-2*dmp.PatchMargin) {
 		padding += dmp.PatchMargin
-		maxStart := max(0, patch.Start2-padding)
-		minEnd := min(len(text), patch.Start2+patch.Length1+padding)
+		maxStart := runeBoundaryBefore(text, max(0, patch.Start2-padding))
+		minEnd := runeBoundaryAfter(text, min(len(text), patch.Start2+patch.Length1+padding))
 		pattern = text[maxStart:minEnd]
 	}
 	// Add one chunk for good luck.
 	padding += dmp.PatchMargin
 
 	// Add the prefix.
-	prefix := text[max(0, patch.Start2-padding):patch.Start2]
+	prefix := text[runeBoundaryBefore(text, max(0, patch.Start2-padding)):patch.Start2]
 	if len(prefix) != 0 {
 		patch.diffs = append([]Diff{Diff{DiffEqual, prefix}}, patch.diffs...)
 	}
 	// Add the suffix.
-	suffix := text[patch.Start2+patch.Length1 : min(len(text), patch.Start2+patch.Length1+padding)]
+	suffix := text[patch.Start2+patch.Length1 : runeBoundaryAfter(text, min(len(text), patch.Start2+patch.Length1+padding))]
 	if len(suffix) != 0 {
 		patch.diffs = append(patch.diffs, Diff{DiffEqual, suffix})
 	}
@@ -128,7 +143,7 @@ func (dmp *DiffMatchPatch) PatchMake(opt ...interface{}) []Patch {
 			}
 			return dmp.PatchMake(text1, diffs)
 		case []Diff:
-			return dmp.patchMake2(text1, t)
+			return dmp.patchesToUnit(dmp.patchMake2(text1, t), text1, UnitByte, dmp.PatchUnit)
 		}
 	} else if len(opt) == 3 {
 		return dmp.PatchMake(opt[0], opt[2])
@@ -137,7 +152,7 @@ func (dmp *DiffMatchPatch) PatchMake(opt ...interface{}) []Patch {
 }
 
 // patchMake2 computes a list of patches to turn text1 into text2.
-// text2 is not provided, diffs are the delta between text1 and text2.
+// text2 is not provided, diffs are the delta between text1 and text2. The patches count bytes.
 func (dmp *DiffMatchPatch) patchMake2(text1 string, diffs []Diff) []Patch {
 	// Check for null inputs not needed since null can't be passed in C#.
 	patches := []Patch{}
@@ -180,7 +195,7 @@ func (dmp *DiffMatchPatch) patchMake2(text1 string, diffs []Diff) []Patch {
 			if len(aDiff.Text) >= 2*dmp.PatchMargin {
 				// Time for a new patch.
 				if len(patch.diffs) != 0 {
-					patch = dmp.PatchAddContext(patch, prepatchText)
+					patch = dmp.patchAddContext(patch, prepatchText)
 					patches = append(patches, patch)
 					patch = Patch{}
 					// Unlike Unidiff, our patch lists have a rolling context. http://code.google.com/p/google-diff-match-patch/wiki/Unidiff Update prepatch text & pos to reflect the application of the just completed patch.
@@ -201,7 +216,7 @@ func (dmp *DiffMatchPatch) patchMake2(text1 string, diffs []Diff) []Patch {
 
 	// Pick up the leftover patch if not empty.
 	if len(patch.diffs) != 0 {
-		patch = dmp.PatchAddContext(patch, prepatchText)
+		patch = dmp.patchAddContext(patch, prepatchText)
 		patches = append(patches, patch)
 	}
 
@@ -228,80 +243,338 @@ func (dmp *DiffMatchPatch) PatchDeepCopy(patches []Patch) []Patch {
 	return patchesCopy
 }
 
+// PatchInvert returns patches which undo the given patches, i.e. turn the text they were made into back into the text they were made from.
+// The positions of each patch count from the start of a text which the preceding patches have already been applied to. Once inverted, the preceding patches change the length of the text the other way round, which shifts the positions.
+func (dmp *DiffMatchPatch) PatchInvert(patches []Patch) []Patch {
+	inverted := dmp.patchTrimContext(patches)
+	// How much longer the preceding patches made the text.
+	delta := 0
+	for i := range inverted {
+		aPatch := &inverted[i]
+		aPatch.diffs = dmp.DiffInvert(aPatch.diffs)
+		aPatch.Start1 -= delta
+		aPatch.Start2 -= delta
+		aPatch.Length1, aPatch.Length2 = aPatch.Length2, aPatch.Length1
+		delta += aPatch.Length1 - aPatch.Length2
+	}
+	return inverted
+}
+
+// patchTrimContext returns a copy of patches without the context which overlaps the changes of a neighbouring patch.
+// The context in front of a patch is taken from the text the preceding patches were applied to, the context at its back from the text the following patches are yet to be applied to. Once trimmed, the context of every patch holds no matter which of its neighbours are applied.
+func (dmp *DiffMatchPatch) patchTrimContext(patches []Patch) []Patch {
+	// Where the changes of each patch start and end within the text it was made into, leaving out its context.
+	changeStarts := make([]int, len(patches))
+	changeEnds := make([]int, len(patches))
+	for i, aPatch := range patches {
+		changeStarts[i] = aPatch.Start2
+		changeEnds[i] = aPatch.Start2 + aPatch.Length2
+		if n := len(aPatch.diffs); n > 1 {
+			if aPatch.diffs[0].Type == DiffEqual {
+				changeStarts[i] += unitLen(aPatch.diffs[0].Text, dmp.PatchUnit)
+			}
+			if aPatch.diffs[n-1].Type == DiffEqual {
+				changeEnds[i] -= unitLen(aPatch.diffs[n-1].Text, dmp.PatchUnit)
+			}
+		}
+	}
+
+	trimmed := dmp.PatchDeepCopy(patches)
+	for i := range trimmed {
+		aPatch := &trimmed[i]
+		n := len(aPatch.diffs)
+		if n < 2 {
+			continue
+		}
+		// Length of the context to leave out in front and at the back.
+		prefix := 0
+		suffix := 0
+		if i > 0 && aPatch.diffs[0].Type == DiffEqual {
+			text := aPatch.diffs[0].Text
+			prefix = min(max(0, changeEnds[i-1]-aPatch.Start2), unitLen(text, dmp.PatchUnit))
+			aPatch.diffs[0].Text = text[unitIndex(text, prefix, dmp.PatchUnit):]
+		}
+		if i+1 < len(trimmed) && aPatch.diffs[n-1].Type == DiffEqual {
+			text := aPatch.diffs[n-1].Text
+			suffix = min(max(0, aPatch.Start2+aPatch.Length2-changeStarts[i+1]), unitLen(text, dmp.PatchUnit))
+			aPatch.diffs[n-1].Text = text[:unitIndex(text, unitLen(text, dmp.PatchUnit)-suffix, dmp.PatchUnit)]
+			if len(aPatch.diffs[n-1].Text) == 0 {
+				aPatch.diffs = aPatch.diffs[:n-1]
+			}
+		}
+		if len(aPatch.diffs[0].Text) == 0 {
+			aPatch.diffs = aPatch.diffs[1:]
+		}
+
+		aPatch.Start1 += prefix
+		aPatch.Start2 += prefix
+		aPatch.Length1 -= prefix + suffix
+		aPatch.Length2 -= prefix + suffix
+	}
+	return trimmed
+}
+
 // PatchApply merges a set of patches onto the text.  Returns a patched text, as well as an array of true/false values indicating which patches were applied.
 func (dmp *DiffMatchPatch) PatchApply(patches []Patch, text string) (string, []bool) {
+	text, results, _ := dmp.PatchApplyContext(context.Background(), patches, text)
+	return text, results
+}
+
+// PatchApplyContext merges a set of patches onto the text and stops once ctx is done.
+// Returns a patched text, as well as an array of true/false values indicating which patches were applied. If ctx is done before all patches are applied, the patch in progress and the remaining ones are left unapplied and ctx.Err() is returned.
+func (dmp *DiffMatchPatch) PatchApplyContext(ctx context.Context, patches []Patch, text string) (string, []bool, error) {
+	text, results, err := dmp.PatchApplyResultsContext(ctx, patches, text)
+	return text, patchResultsApplied(results), err
+}
+
+// PatchFailure tells why a patch was not applied.
+type PatchFailure int8
+
+//go:generate stringer -type=PatchFailure -trimprefix=PatchFailure
+
+const (
+	// PatchFailureNone means that the patch was applied.
+	PatchFailureNone PatchFailure = iota
+	// PatchFailureNoMatch means that the text the patch expected was not found.
+	PatchFailureNoMatch
+	// PatchFailureTrailingContext means that the start of a patch longer than MatchMaxBits was found, but not its end.
+	PatchFailureTrailingContext
+	// PatchFailureTooDifferent means that the text between the ends of a patch longer than MatchMaxBits differs from what the patch expected by more than PatchDeleteThreshold.
+	PatchFailureTooDifferent
+	// PatchFailureCanceled means that the context was done before the patch was applied.
+	PatchFailureCanceled
+)
+
+// PatchResult reports how a patch was applied.
+type PatchResult struct {
+	// Patch is the index of the patch the result belongs to among the patches which were applied.
+	// Patches longer than MatchMaxBits are split up and applied piece by piece, so several results can belong to the same patch.
+	Patch int
+	// Applied is true if the patch was applied.
+	Applied bool
+	// Failure tells why the patch was not applied.
+	Failure PatchFailure
+	// ExpectedLoc is where the patch was expected, taking into account how far the preceding patches were off.
+	// Delta is how far the patch was found off ExpectedLoc.
+	ExpectedLoc int
+	Delta       int
+	// Match is where the text the patch expected was found, Start is -1 if it was not found.
+	// The location counts from the start of the text as patched by the preceding patches, the errors and score tell how much fuzz it took to find the patch.
+	Match Match
+	// Perfect is true if the text the patch expected was found as it is, so that no diff was needed to apply the patch.
+	Perfect bool
+	// Ratio is the Levenshtein distance between the text the patch expected and the text it was applied to, relative to the length of the former.
+	// Patches longer than MatchMaxBits are not applied if it exceeds PatchDeleteThreshold.
+	Ratio float64
+}
+
+// PatchApplyResults merges a set of patches onto the text like PatchApply. Returns a patched text, as well as how each patch was applied.
+// Patches are split up like PatchSplitMax does before they are applied, so there can be more results than patches. The Patch field of a result tells which patch it belongs to.
+func (dmp *DiffMatchPatch) PatchApplyResults(patches []Patch, text string) (string, []PatchResult) {
+	text, results, _ := dmp.PatchApplyResultsContext(context.Background(), patches, text)
+	return text, results
+}
+
+// PatchApplyResultsContext is like PatchApplyResults but stops once ctx is done, in which case the patch in progress and the remaining ones are left unapplied with PatchFailureCanceled and ctx.Err() is returned.
+func (dmp *DiffMatchPatch) PatchApplyResultsContext(ctx context.Context, patches []Patch, text string) (string, []PatchResult, error) {
+	if dmp.PatchUnit != UnitByte {
+		patches = dmp.patchesToUnit(dmp.PatchDeepCopy(patches), text, dmp.PatchUnit, UnitByte)
+	}
+	return dmp.patchApply(ctx, patches, text, true, false)
+}
+
+// patchResultsApplied returns which patches were applied.
+func patchResultsApplied(results []PatchResult) []bool {
+	applied := make([]bool, len(results))
+	for i, result := range results {
+		applied[i] = result.Applied
+	}
+	return applied
+}
+
+// patchApply merges a set of patches which count bytes onto the text. If splitMax is false, patches longer than MatchMaxBits are located by their ends and applied as a whole.
+// Nor is the text padded then, since padding is only right for patches which lack context at the edges of the text, whereas such patches, e.g. unified hunks, may lack context anywhere.
+// If binary is true, the text and patches are diffed byte by byte instead of as UTF-8, and the matches are reported in bytes rather than PatchUnit.
+func (dmp *DiffMatchPatch) patchApply(ctx context.Context, patches []Patch, text string, splitMax, binary bool) (string, []PatchResult, error) {
 	if len(patches) == 0 {
-		return text, []bool{}
+		return text, []PatchResult{}, ctx.Err()
 	}
 
 	// Deep copy the patches so that no changes are made to originals.
 	patches = dmp.PatchDeepCopy(patches)
 
-	nullPadding := dmp.PatchAddPadding(patches)
+	nullPadding := ""
+	if splitMax {
+		nullPadding = dmp.patchAddPadding(patches, UnitByte)
+	}
 	text = nullPadding + text + nullPadding
-	patches = dmp.PatchSplitMax(patches)
+	// Index of the patch passed in which each patch to apply was split off.
+	indices := make([]int, len(patches))
+	for i := range indices {
+		indices[i] = i
+	}
+	if splitMax {
+		var split []Patch
+		indices = indices[:0]
+		for i, aPatch := range patches {
+			for _, piece := range dmp.patchSplitMax([]Patch{aPatch}, UnitByte) {
+				split = append(split, piece)
+				indices = append(indices, i)
+			}
+		}
+		patches = split
+	}
+
+	// Binary data is matched byte by byte.
+	matchUnit := dmp.MatchUnit
+	if binary {
+		matchUnit = UnitByte
+	}
 
 	x := 0
 	// delta keeps track of the offset between the expected and actual location of the previous patch.  If there are patches expected at positions 10 and 20, but the first patch was found at 12, delta is 2 and the second patch has an effective expected position of 22.
 	delta := 0
-	results := make([]bool, len(patches))
+	results := make([]PatchResult, len(patches))
+	for i := range results {
+		results[i] = PatchResult{Patch: indices[i], Failure: PatchFailureCanceled, Match: Match{Start: -1, End: -1}}
+	}
+	var err error
 	for _, aPatch := range patches {
+		if err = ctx.Err(); err != nil {
+			// Leave this and all following patches unapplied.
+			break
+		}
 		expectedLoc := aPatch.Start2 + delta
 		text1 := dmp.DiffText1(aPatch.diffs)
-		var startLoc int
+		var match Match
 		endLoc := -1
-		if len(text1) > dmp.MatchMaxBits {
-			// PatchSplitMax will only provide an oversized pattern in the case of a monster delete.
-			startLoc = dmp.MatchMain(text, text1[:dmp.MatchMaxBits], expectedLoc)
-			if startLoc != -1 {
-				endLoc = dmp.MatchMain(text,
-					text1[len(text1)-dmp.MatchMaxBits:], expectedLoc+len(text1)-dmp.MatchMaxBits)
-				if endLoc == -1 || startLoc >= endLoc {
+		failure := PatchFailureNoMatch
+		// Length of the trailing pattern which locates the end of an oversized patch.
+		endLength := dmp.MatchMaxBits
+		if dmp.MatchMaxBits != 0 && unitLen(text1, matchUnit) > dmp.MatchMaxBits {
+			// PatchSplitMax will only provide an oversized pattern in the case of a monster delete or if it was skipped.
+			head, tail := text1[:dmp.MatchMaxBits], text1[len(text1)-dmp.MatchMaxBits:]
+			if matchUnit != UnitByte {
+				// Runes and grapheme clusters cut in half would not match.
+				head, tail = unitPrefix(text1, dmp.MatchMaxBits, matchUnit), unitSuffix(text1, dmp.MatchMaxBits, matchUnit)
+				endLength = len(tail)
+			}
+			match, err = dmp.matchMain(ctx, text, head, expectedLoc, matchUnit)
+			if match.Start != -1 && err == nil {
+				var endMatch Match
+				endMatch, err = dmp.matchMain(ctx, text, tail, expectedLoc+len(text1)-endLength, matchUnit)
+				endLoc = endMatch.Start
+				if endLoc == -1 || match.Start >= endLoc {
 					// Can't find valid trailing context.  Drop this patch.
-					startLoc = -1
+					match = Match{Start: -1, End: -1}
+					failure = PatchFailureTrailingContext
+				} else {
+					// The errors of both ends add up, and the worse score counts.
+					match.End = endMatch.End
+					match.Errors += endMatch.Errors
+					match.Accuracy = float64(match.Errors) / float64(unitLen(head, matchUnit)+unitLen(tail, matchUnit))
+					match.Score = math.Max(match.Score, endMatch.Score)
 				}
 			}
 		} else {
-			startLoc = dmp.MatchMain(text, text1, expectedLoc)
+			match, err = dmp.matchMain(ctx, text, text1, expectedLoc, matchUnit)
 		}
+		if err != nil {
+			// A search which was cut short may have found the wrong place, so leave this and all following patches unapplied.
+			break
+		}
+		startLoc := match.Start
+		if !binary && startLoc != -1 {
+			// A fuzzy match may start or end within a rune.
+			// The end is moved to the end of its rune once the matched text is cut out below.
+			startLoc = runeBoundaryBefore(text, startLoc)
+			match.Start, match.End = startLoc, runeBoundaryAfter(text, match.End)
+		}
+		expected := Match{Start: expectedLoc, End: expectedLoc}
+		if !binary {
+			results[x].Match = dmp.patchMatchToUnit(text, len(nullPadding), match)
+			expected = dmp.patchMatchToUnit(text, len(nullPadding), expected)
+		} else {
+			results[x].Match = patchMatchUnpadded(text, len(nullPadding), match)
+			expected = patchMatchUnpadded(text, len(nullPadding), expected)
+		}
+		results[x].ExpectedLoc = expected.Start
 		if startLoc == -1 {
 			// No match found.  :(
-			results[x] = false
+			results[x].Applied = false
+			results[x].Failure = failure
 			// Subtract the delta for this failed patch from subsequent patches.
 			delta -= aPatch.Length2 - aPatch.Length1
 		} else {
 			// Found a match.  :)
-			results[x] = true
+			results[x].Applied = true
+			results[x].Failure = PatchFailureNone
+			results[x].Delta = results[x].Match.Start - results[x].ExpectedLoc
 			delta = startLoc - expectedLoc
 			var text2 string
 			if endLoc == -1 {
-				text2 = text[startLoc:int(math.Min(float64(startLoc+len(text1)), float64(len(text))))]
+				endIndex := startLoc + len(text1)
+				if dmp.MatchMaxBits == 0 {
+					// The match can be longer than the pattern by as many insertions as errors are acceptable.
+					endIndex += int(dmp.MatchThreshold * float64(len(text1)))
+				}
+				text2 = text[startLoc:int(math.Min(float64(endIndex), float64(len(text))))]
 			} else {
-				text2 = text[startLoc:int(math.Min(float64(endLoc+dmp.MatchMaxBits), float64(len(text))))]
+				text2 = text[startLoc:int(math.Min(float64(endLoc+endLength), float64(len(text))))]
 			}
-			if text1 == text2 {
+			if !binary {
+				text2 = text[startLoc:runeBoundaryAfter(text, startLoc+len(text2))]
+			}
+			if strings.HasPrefix(text2, text1) {
 				// Perfect match, just shove the Replacement text in.
+				results[x].Perfect = true
 				text = text[:startLoc] + dmp.DiffText2(aPatch.diffs) + text[startLoc+len(text1):]
 			} else {
 				// Imperfect match.  Run a diff to get a framework of equivalent indices.
-				diffs := dmp.DiffMain(text1, text2, false)
-				if len(text1) > dmp.MatchMaxBits && float64(dmp.DiffLevenshtein(diffs))/float64(len(text1)) > dmp.PatchDeleteThreshold {
+				var diffs []Diff
+				length1 := len(text1)
+				if binary {
+					diffs, err = dmp.diffMainBytes(ctx, text1, text2)
+				} else {
+					diffs, err = dmp.DiffMainContext(ctx, text1, text2, false)
+					// DiffLevenshtein counts runes.
+					length1 = utf8.RuneCountInString(text1)
+				}
+				if err != nil {
+					// A diff which was cut short would map the patch onto the wrong indices, so leave this and all following patches unapplied.
+					results[x] = PatchResult{Patch: indices[x], Failure: PatchFailureCanceled, Match: Match{Start: -1, End: -1}}
+					break
+				}
+				results[x].Ratio = float64(dmp.DiffLevenshtein(diffs)) / float64(length1)
+				if endLoc != -1 && results[x].Ratio > dmp.PatchDeleteThreshold {
 					// The end points match, but the content is unacceptably bad.
-					results[x] = false
+					results[x].Applied = false
+					results[x].Failure = PatchFailureTooDifferent
 				} else {
-					diffs = dmp.DiffCleanupSemanticLossless(diffs)
+					if binary {
+						diffs = dmp.diffCleanupSemanticLosslessBytes(diffs)
+					} else {
+						diffs = dmp.DiffCleanupSemanticLossless(diffs)
+					}
 					index1 := 0
 					for _, aDiff := range aPatch.diffs {
 						if aDiff.Type != DiffEqual {
-							index2 := dmp.DiffXIndex(diffs, index1)
+							// The indices of the diff may run past the text which the earlier changes of the patch already edited, or fall within a rune.
+							// Changes are kept off the padding, which is stripped off by its length.
+							startIndex := min(max(len(nullPadding), startLoc+diffXIndex(diffs, index1, UnitByte)), len(text)-len(nullPadding))
+							if !binary {
+								startIndex = runeBoundaryBefore(text, startIndex)
+							}
 							if aDiff.Type == DiffInsert {
 								// Insertion
-								text = text[:startLoc+index2] + aDiff.Text + text[startLoc+index2:]
+								text = text[:startIndex] + aDiff.Text + text[startIndex:]
 							} else if aDiff.Type == DiffDelete {
 								// Deletion
-								startIndex := startLoc + index2
-								text = text[:startIndex] +
-									text[startIndex+dmp.DiffXIndex(diffs, index1+len(aDiff.Text))-index2:]
+								endIndex := min(max(startIndex, startLoc+diffXIndex(diffs, index1+len(aDiff.Text), UnitByte)), len(text)-len(nullPadding))
+								if !binary {
+									endIndex = runeBoundaryAfter(text, endIndex)
+								}
+								text = text[:startIndex] + text[endIndex:]
 							}
 						}
 						if aDiff.Type != DiffDelete {
@@ -315,16 +588,36 @@ func (dmp *DiffMatchPatch) PatchApply(patches []Patch, text string) (string, []b
 	}
 	// Strip the padding off.
 	text = text[len(nullPadding) : len(nullPadding)+(len(text)-2*len(nullPadding))]
-	return text, results
+	return text, results, err
+}
+
+// patchMatchUnpadded moves a match in text with padding of the given length around it so that it counts from the start of text without padding.
+func patchMatchUnpadded(text string, padding int, match Match) Match {
+	if match.Start == -1 {
+		return match
+	}
+	match.Start = min(max(match.Start-padding, 0), len(text)-2*padding)
+	match.End = min(max(match.End-padding, match.Start), len(text)-2*padding)
+	return match
+}
+
+// patchMatchToUnit converts a match in text with padding of the given length around it from bytes to PatchUnit, counting from the start of text without padding.
+func (dmp *DiffMatchPatch) patchMatchToUnit(text string, padding int, match Match) Match {
+	return dmp.matchToUnit(text[padding:len(text)-padding], patchMatchUnpadded(text, padding, match))
 }
 
 // PatchAddPadding adds some padding on text start and end so that edges can match something.
 // Intended to be called only from within patchApply.
 func (dmp *DiffMatchPatch) PatchAddPadding(patches []Patch) string {
+	return dmp.patchAddPadding(patches, dmp.PatchUnit)
+}
+
+// patchAddPadding adds some padding to patches which count in the given unit.
+func (dmp *DiffMatchPatch) patchAddPadding(patches []Patch, unit Unit) string {
 	paddingLength := dmp.PatchMargin
 	nullPadding := ""
 	for x := 1; x <= paddingLength; x++ {
-		nullPadding += string(x)
+		nullPadding += string(rune(x))
 	}
 
 	// Bump all the patches forward.
@@ -341,10 +634,10 @@ func (dmp *DiffMatchPatch) PatchAddPadding(patches []Patch) string {
 		patches[0].Start2 -= paddingLength // Should be 0.
 		patches[0].Length1 += paddingLength
 		patches[0].Length2 += paddingLength
-	} else if paddingLength > len(patches[0].diffs[0].Text) {
+	} else if firstLength := unitLen(patches[0].diffs[0].Text, unit); paddingLength > firstLength {
 		// Grow first equality.
-		extraLength := paddingLength - len(patches[0].diffs[0].Text)
-		patches[0].diffs[0].Text = nullPadding[len(patches[0].diffs[0].Text):] + patches[0].diffs[0].Text
+		extraLength := paddingLength - firstLength
+		patches[0].diffs[0].Text = nullPadding[firstLength:] + patches[0].diffs[0].Text
 		patches[0].Start1 -= extraLength
 		patches[0].Start2 -= extraLength
 		patches[0].Length1 += extraLength
@@ -358,10 +651,9 @@ func (dmp *DiffMatchPatch) PatchAddPadding(patches []Patch) string {
 		patches[last].diffs = append(patches[last].diffs, Diff{DiffEqual, nullPadding})
 		patches[last].Length1 += paddingLength
 		patches[last].Length2 += paddingLength
-	} else if paddingLength > len(patches[last].diffs[len(patches[last].diffs)-1].Text) {
+	} else if lastLength := unitLen(patches[last].diffs[len(patches[last].diffs)-1].Text, unit); paddingLength > lastLength {
 		// Grow last equality.
-		lastDiff := patches[last].diffs[len(patches[last].diffs)-1]
-		extraLength := paddingLength - len(lastDiff.Text)
+		extraLength := paddingLength - lastLength
 		patches[last].diffs[len(patches[last].diffs)-1].Text += nullPadding[:extraLength]
 		patches[last].Length1 += extraLength
 		patches[last].Length2 += extraLength
@@ -373,7 +665,16 @@ func (dmp *DiffMatchPatch) PatchAddPadding(patches []Patch) string {
 // PatchSplitMax looks through the patches and breaks up any which are longer than the maximum limit of the match algorithm.
 // Intended to be called only from within patchApply.
 func (dmp *DiffMatchPatch) PatchSplitMax(patches []Patch) []Patch {
+	return dmp.patchSplitMax(patches, dmp.PatchUnit)
+}
+
+// patchSplitMax breaks up patches which count in the given unit, without splitting a rune.
+func (dmp *DiffMatchPatch) patchSplitMax(patches []Patch, unit Unit) []Patch {
 	patchSize := dmp.MatchMaxBits
+	if patchSize == 0 {
+		// Patches of any length can be matched.
+		return patches
+	}
 	for x := 0; x < len(patches); x++ {
 		if patches[x].Length1 <= patchSize {
 			continue
@@ -390,11 +691,12 @@ func (dmp *DiffMatchPatch) PatchSplitMax(patches []Patch) []Patch {
 			// Create one of several smaller patches.
 			patch := Patch{}
 			empty := true
-			patch.Start1 = Start1 - len(precontext)
-			patch.Start2 = Start2 - len(precontext)
+			precontextLength := unitLen(precontext, unit)
+			patch.Start1 = Start1 - precontextLength
+			patch.Start2 = Start2 - precontextLength
 			if len(precontext) != 0 {
-				patch.Length1 = len(precontext)
-				patch.Length2 = len(precontext)
+				patch.Length1 = precontextLength
+				patch.Length2 = precontextLength
 				patch.diffs = append(patch.diffs, Diff{DiffEqual, precontext})
 			}
 			for len(bigpatch.diffs) != 0 && patch.Length1 < patchSize-dmp.PatchMargin {
@@ -402,27 +704,28 @@ func (dmp *DiffMatchPatch) PatchSplitMax(patches []Patch) []Patch {
 				diffText := bigpatch.diffs[0].Text
 				if diffType == DiffInsert {
 					// Insertions are harmless.
-					patch.Length2 += len(diffText)
-					Start2 += len(diffText)
+					patch.Length2 += unitLen(diffText, unit)
+					Start2 += unitLen(diffText, unit)
 					patch.diffs = append(patch.diffs, bigpatch.diffs[0])
 					bigpatch.diffs = bigpatch.diffs[1:]
 					empty = false
-				} else if diffType == DiffDelete && len(patch.diffs) == 1 && patch.diffs[0].Type == DiffEqual && len(diffText) > 2*patchSize {
+				} else if diffType == DiffDelete && len(patch.diffs) == 1 && patch.diffs[0].Type == DiffEqual && unitLen(diffText, unit) > 2*patchSize {
 					// This is a large deletion.  Let it pass in one chunk.
-					patch.Length1 += len(diffText)
-					Start1 += len(diffText)
+					patch.Length1 += unitLen(diffText, unit)
+					Start1 += unitLen(diffText, unit)
 					empty = false
 					patch.diffs = append(patch.diffs, Diff{diffType, diffText})
 					bigpatch.diffs = bigpatch.diffs[1:]
 				} else {
 					// Deletion or equality.  Only take as much as we can stomach.
-					diffText = diffText[:min(len(diffText), patchSize-patch.Length1-dmp.PatchMargin)]
+					diffText = unitPrefix(diffText, patchSize-patch.Length1-dmp.PatchMargin, unit)
+					diffLength := unitLen(diffText, unit)
 
-					patch.Length1 += len(diffText)
-					Start1 += len(diffText)
+					patch.Length1 += diffLength
+					Start1 += diffLength
 					if diffType == DiffEqual {
-						patch.Length2 += len(diffText)
-						Start2 += len(diffText)
+						patch.Length2 += diffLength
+						Start2 += diffLength
 					} else {
 						empty = false
 					}
@@ -436,20 +739,14 @@ func (dmp *DiffMatchPatch) PatchSplitMax(patches []Patch) []Patch {
 				}
 			}
 			// Compute the head context for the next patch.
-			precontext = dmp.DiffText2(patch.diffs)
-			precontext = precontext[max(0, len(precontext)-dmp.PatchMargin):]
+			precontext = unitSuffix(dmp.DiffText2(patch.diffs), dmp.PatchMargin, unit)
 
-			postcontext := ""
 			// Append the end context for this patch.
-			if len(dmp.DiffText1(bigpatch.diffs)) > dmp.PatchMargin {
-				postcontext = dmp.DiffText1(bigpatch.diffs)[:dmp.PatchMargin]
-			} else {
-				postcontext = dmp.DiffText1(bigpatch.diffs)
-			}
+			postcontext := unitPrefix(dmp.DiffText1(bigpatch.diffs), dmp.PatchMargin, unit)
 
 			if len(postcontext) != 0 {
-				patch.Length1 += len(postcontext)
-				patch.Length2 += len(postcontext)
+				patch.Length1 += unitLen(postcontext, unit)
+				patch.Length2 += unitLen(postcontext, unit)
 				if len(patch.diffs) != 0 && patch.diffs[len(patch.diffs)-1].Type == DiffEqual {
 					patch.diffs[len(patch.diffs)-1].Text += postcontext
 				} else {
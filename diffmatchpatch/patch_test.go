@@ -9,9 +9,12 @@
 package diffmatchpatch
 
 import (
+	"context"
 	"fmt"
+	"math/rand"
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -124,6 +127,7 @@ func TestPatchAddContext(t *testing.T) {
 
 		Patch string
 		Text  string
+		Unit  Unit
 
 		Expected string
 	}
@@ -132,11 +136,16 @@ func TestPatchAddContext(t *testing.T) {
 	dmp.PatchMargin = 4
 
 	for i, tc := range []TestCase{
-		{"Simple case", "@@ -21,4 +21,10 @@\n-jump\n+somersault\n", "The quick brown fox jumps over the lazy dog.", "@@ -17,12 +17,18 @@\n fox \n-jump\n+somersault\n s ov\n"},
-		{"Not enough trailing context", "@@ -21,4 +21,10 @@\n-jump\n+somersault\n", "The quick brown fox jumps.", "@@ -17,10 +17,16 @@\n fox \n-jump\n+somersault\n s.\n"},
-		{"Not enough leading context", "@@ -3 +3,2 @@\n-e\n+at\n", "The quick brown fox jumps.", "@@ -1,7 +1,8 @@\n Th\n-e\n+at\n  qui\n"},
-		{"Ambiguity", "@@ -3 +3,2 @@\n-e\n+at\n", "The quick brown fox jumps.  The quick brown fox crashes.", "@@ -1,27 +1,28 @@\n Th\n-e\n+at\n  quick brown fox jumps. \n"},
+		{"Simple case", "@@ -21,4 +21,10 @@\n-jump\n+somersault\n", "The quick brown fox jumps over the lazy dog.", UnitByte, "@@ -17,12 +17,18 @@\n fox \n-jump\n+somersault\n s ov\n"},
+		{"Not enough trailing context", "@@ -21,4 +21,10 @@\n-jump\n+somersault\n", "The quick brown fox jumps.", UnitByte, "@@ -17,10 +17,16 @@\n fox \n-jump\n+somersault\n s.\n"},
+		{"Not enough leading context", "@@ -3 +3,2 @@\n-e\n+at\n", "The quick brown fox jumps.", UnitByte, "@@ -1,7 +1,8 @@\n Th\n-e\n+at\n  qui\n"},
+		{"Ambiguity", "@@ -3 +3,2 @@\n-e\n+at\n", "The quick brown fox jumps.  The quick brown fox crashes.", UnitByte, "@@ -1,27 +1,28 @@\n Th\n-e\n+at\n  quick brown fox jumps. \n"},
+		{"Multibyte context in bytes", "@@ -7,3 +7,6 @@\n-語\n+言葉\n", "日本語の文章です。", UnitByte, "@@ -1,15 +1,18 @@\n %E6%97%A5%E6%9C%AC\n-%E8%AA%9E\n+%E8%A8%80%E8%91%89\n %E3%81%AE%E6%96%87\n"},
+		{"Multibyte context in runes", "@@ -3 +3,2 @@\n-語\n+言葉\n", "日本語の文章です。", UnitRune, "@@ -1,5 +1,6 @@\n %E6%97%A5%E6%9C%AC\n-%E8%AA%9E\n+%E8%A8%80%E8%91%89\n %E3%81%AE%E6%96%87\n"},
+		{"Ambiguity in runes", "@@ -3 +3,2 @@\n-語\n+言葉\n", "日本語の文章です。日本語の本です。", UnitRune, "@@ -1,6 +1,7 @@\n %E6%97%A5%E6%9C%AC\n-%E8%AA%9E\n+%E8%A8%80%E8%91%89\n %E3%81%AE%E6%96%87%E7%AB%A0\n"},
+		{"Emoji context in runes", "@@ -3 +3 @@\n-🐶\n+🐱\n", "🦊 🐶 🦊", UnitRune, "@@ -1,5 +1,5 @@\n %F0%9F%A6%8A \n-%F0%9F%90%B6\n+%F0%9F%90%B1\n  %F0%9F%A6%8A\n"},
 	} {
+		dmp.PatchUnit = tc.Unit
 		patches, err := dmp.PatchFromText(tc.Patch)
 		assert.Nil(t, err)
 
@@ -337,3 +346,375 @@ func TestPatchApply(t *testing.T) {
 		assert.Equal(t, tc.ExpectedApplies, actualApplies, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
 	}
 }
+
+func TestPatchApplyLongPatterns(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1    string
+		Text2    string
+		TextBase string
+
+		Expected        string
+		ExpectedApplies []bool
+	}
+
+	dmp := New()
+	dmp.MatchMaxBits = 0
+
+	for i, tc := range []TestCase{
+		{"Exact match", "The quick brown fox jumps over the lazy dog.", "That quick brown fox jumped over a lazy dog.", "The quick brown fox jumps over the lazy dog.", "That quick brown fox jumped over a lazy dog.", []bool{true, true}},
+		{"Big delete, small Diff", "x1234567890123456789012345678901234567890123456789012345678901234567890y", "xabcy", "x123456789012345678901234567890-----++++++++++-----123456789012345678901234567890y", "xabcy", []bool{true}},
+		{"Big delete, big Diff", "x1234567890123456789012345678901234567890123456789012345678901234567890y", "xabcy", "x12345678901234567890---------------++++++++++---------------12345678901234567890y", "x12345678901234567890---------------++++++++++---------------12345678901234567890y", []bool{false}},
+		{"Long hunk", "Start. The quick brown fox jumps over the lazy dog and runs away into the dark forest. End.", "Start. A slow grey wolf sneaks around the sleeping dog and hides within the old woods. End.", "Preface. Start. The quick brown fox jumps over the lazy dog and runs off into the dark forest. End.", "Preface. Start. A slow grey wolf sneaks around the sleeping dog and hides within the old woods. End.", []bool{true}},
+	} {
+		patches := dmp.PatchMake(tc.Text1, tc.Text2)
+		assert.Equal(t, patches, dmp.PatchSplitMax(dmp.PatchDeepCopy(patches)), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		actual, actualApplies := dmp.PatchApply(patches, tc.TextBase)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedApplies, actualApplies, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Patches located as a whole withstand more drift than those located in pieces.
+	text1, text2 := speedtestTexts()
+	preamble := strings.Repeat("Preamble: 0123456789.\n", 20)
+
+	dmp.DiffTimeout = 0
+	levenshtein := map[int]int{}
+	for _, maxBits := range []int{32, 0} {
+		dmp.MatchMaxBits = maxBits
+
+		actual, actualApplies := dmp.PatchApply(dmp.PatchMake(text1, text2), preamble+text1)
+		levenshtein[maxBits] = dmp.DiffLevenshtein(dmp.DiffMain(actual, preamble+text2, false))
+		if maxBits == 0 {
+			assert.NotContains(t, actualApplies, false)
+		}
+	}
+	assert.True(t, levenshtein[0] < levenshtein[32]/10, fmt.Sprintf("%v", levenshtein))
+}
+
+func TestPatchApplyContext(t *testing.T) {
+	dmp := New()
+
+	patches := dmp.PatchMake("The quick brown fox jumps over the lazy dog.", "That quick brown fox jumped over a lazy dog.")
+
+	actual, actualApplies, err := dmp.PatchApplyContext(context.Background(), patches, "The quick red rabbit jumps over the tired tiger.")
+	assert.NoError(t, err)
+	assert.Equal(t, "That quick red rabbit jumped over a tired tiger.", actual)
+	assert.Equal(t, []bool{true, true}, actualApplies)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	actual, actualApplies, err = dmp.PatchApplyContext(ctx, patches, "The quick red rabbit jumps over the tired tiger.")
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, "The quick red rabbit jumps over the tired tiger.", actual)
+	assert.Equal(t, []bool{false, false}, actualApplies)
+
+	// Cancel at every point while the patches are applied, which leaves the patch in progress unapplied rather than applying it with a search or diff which was cut short.
+	// Without DiffTimeout the diffs check the context directly.
+	dmp.DiffTimeout = 0
+	expected := []string{"The quick brawn fix jumpz ovr the lazy dog.", "The quick brawn fax jumped ovr the lazy dog."}
+	patches = dmp.PatchMake("The quick brown fox jumps over the lazy dog.", "The quick brown fax jumped over the lazy dog.")
+	for checks := 0; ; checks++ {
+		actual, actualApplies, err := dmp.PatchApplyContext(&countdownContext{context.Background(), checks}, patches, expected[0])
+		if err == nil {
+			assert.Equal(t, expected[1], actual)
+			assert.Equal(t, []bool{true}, actualApplies)
+			break
+		}
+		assert.Equal(t, context.Canceled, err)
+		assert.Equal(t, expected[0], actual, fmt.Sprintf("Canceled after %d checks", checks))
+		assert.Equal(t, []bool{false}, actualApplies, fmt.Sprintf("Canceled after %d checks", checks))
+	}
+}
+
+// countdownContext is a context which is canceled once it has been checked for errors a given number of times.
+type countdownContext struct {
+	context.Context
+
+	checks int
+}
+
+func (ctx *countdownContext) Err() error {
+	if ctx.checks <= 0 {
+		return context.Canceled
+	}
+	ctx.checks--
+	return nil
+}
+
+func TestPatchApplyResults(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1    string
+		Text2    string
+		TextBase string
+
+		Expected        string
+		ExpectedResults []PatchResult
+	}
+
+	dmp := New()
+
+	// Scores are summed up at runtime.
+	partialAccuracy, movedAccuracy := 3.0/18, 2.0/13
+
+	for i, tc := range []TestCase{
+		{"Null case", "", "", "Hello world.", "Hello world.", []PatchResult{}},
+		{"Exact match", "The quick brown fox jumps over the lazy dog.", "That quick brown fox jumped over a lazy dog.", "The quick brown fox jumps over the lazy dog.", "That quick brown fox jumped over a lazy dog.", []PatchResult{
+			{Applied: true, ExpectedLoc: 0, Match: Match{Start: 0, End: 11}, Perfect: true},
+			{Patch: 1, Applied: true, ExpectedLoc: 21, Match: Match{Start: 21, End: 39}, Perfect: true},
+		}},
+		{"Partial match", "The quick brown fox jumps over the lazy dog.", "That quick brown fox jumped over a lazy dog.", "The quick red rabbit jumps over the tired tiger.", "That quick red rabbit jumped over a tired tiger.", []PatchResult{
+			{Applied: true, ExpectedLoc: 0, Match: Match{Start: 0, End: 11, Errors: 1, Accuracy: 1.0 / 13, Score: 1.0 / 13}, Ratio: 1.0 / 13},
+			{Patch: 1, Applied: true, ExpectedLoc: 21, Delta: 1, Match: Match{Start: 22, End: 40, Errors: 3, Accuracy: partialAccuracy, Proximity: 0.001, Score: partialAccuracy + 0.001}, Ratio: 3.0 / 18},
+		}},
+		{"Moved match", "The quick brown fox jumps over the lazy dog.", "That quick brown fox jumped over a lazy dog.", "Prolog. The quick brown fox jumps over the lazy dog.", "Prolog. That quick brown fox jumped over a lazy dog.", []PatchResult{
+			{Applied: true, ExpectedLoc: 0, Delta: 6, Match: Match{Start: 6, End: 19, Errors: 2, Accuracy: movedAccuracy, Proximity: 0.008, Score: movedAccuracy + 0.008}, Ratio: 2.0 / 13},
+			// The second patch is expected where the first one suggests.
+			{Patch: 1, Applied: true, ExpectedLoc: 29, Match: Match{Start: 29, End: 47}, Perfect: true},
+		}},
+		{"Failed match", "The quick brown fox jumps over the lazy dog.", "That quick brown fox jumped over a lazy dog.", "I am the very model of a modern major general.", "I am the very model of a modern major general.", []PatchResult{
+			{Failure: PatchFailureNoMatch, ExpectedLoc: 0, Match: Match{Start: -1, End: -1}},
+			{Patch: 1, Failure: PatchFailureNoMatch, ExpectedLoc: 20, Match: Match{Start: -1, End: -1}},
+		}},
+	} {
+		patches := dmp.PatchMake(tc.Text1, tc.Text2)
+
+		actual, actualResults := dmp.PatchApplyResults(patches, tc.TextBase)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedResults, actualResults, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Results of the pieces of patches longer than MatchMaxBits belong to the patch they were split off.
+	patches := dmp.PatchMake("abc0123456789abcdefghijklmnopqrstuvwxyz0123456789ABCDEFGHIJxyz", "abcxyz")
+	patches = append(dmp.PatchMake("Hello world.", "Hello there."), patches...)
+	patches[1].Start1 += 12
+	patches[1].Start2 += 12
+	_, actualResults := dmp.PatchApplyResults(patches, "Hello world.")
+	assert.True(t, len(actualResults) > 2)
+	for i, result := range actualResults {
+		assert.Equal(t, min(i, 1), result.Patch, fmt.Sprintf("Result #%d", i))
+		assert.Equal(t, i == 0, result.Applied, fmt.Sprintf("Result #%d", i))
+	}
+
+	// Patches longer than MatchMaxBits are located by their ends.
+	patches = dmp.PatchMake("x1234567890123456789012345678901234567890123456789012345678901234567890y", "xabcy")
+
+	_, actualResults = dmp.PatchApplyResults(patches, "x12345678901234567890---------------++++++++++---------------12345678901234567890y")
+	assert.Equal(t, PatchFailureTooDifferent, actualResults[0].Failure)
+	assert.False(t, actualResults[0].Applied)
+	assert.Equal(t, Match{Start: 0, End: 82, Errors: 16, Accuracy: 0.25, Score: 0.26}, actualResults[0].Match)
+	assert.InDelta(t, 40.0/78, actualResults[0].Ratio, 1e-9)
+	assert.True(t, actualResults[0].Ratio > dmp.PatchDeleteThreshold)
+
+	dmp.MatchThreshold = 0.1
+	_, actualResults = dmp.PatchApplyResults(patches, "x1234567890123456789012345678901 The quick brown fox jumps over the lazy dog.")
+	assert.Equal(t, PatchFailureTrailingContext, actualResults[0].Failure)
+	assert.Equal(t, Match{Start: -1, End: -1}, actualResults[0].Match)
+	dmp.MatchThreshold = 0.5
+
+	// Patches which were not tried are canceled.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, actualResults, err := dmp.PatchApplyResultsContext(ctx, patches, "x")
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, []PatchFailure{PatchFailureCanceled, PatchFailureCanceled}, []PatchFailure{actualResults[0].Failure, actualResults[1].Failure})
+	assert.Equal(t, "Canceled", actualResults[0].Failure.String())
+
+	// Locations count in PatchUnit.
+	dmp.PatchUnit = UnitRune
+	patches = dmp.PatchMake("日本語の文章です。短い。", "日本の文章です。短いね。")
+	actual, actualResults := dmp.PatchApplyResults(patches, "前書き。日本語の文章です。短い。")
+	assert.Equal(t, "前書き。日本の文章です。短いね。", actual)
+	assert.Equal(t, []int{4, 11}, []int{actualResults[0].Match.Start, actualResults[1].Match.Start})
+	assert.Equal(t, []int{9, 15}, []int{actualResults[0].Match.End, actualResults[1].Match.End})
+	assert.Equal(t, []int{0, 11}, []int{actualResults[0].ExpectedLoc, actualResults[1].ExpectedLoc})
+	assert.Equal(t, []int{4, 0}, []int{actualResults[0].Delta, actualResults[1].Delta})
+
+	// A patch which is longer than MatchMaxBits since it does not split a rune is located by its ends, and the trailing pattern may start within a rune.
+	dmp.PatchUnit = UnitByte
+	patches, _ = dmp.PatchFromText("@@ -1,35 +1,31 @@\n %F0%9F%A6%8A" + strings.Repeat("a", 23) + "\n-%F0%9F%90%B6\n bcde\n")
+	actual, actualResults = dmp.PatchApplyResults(patches, "\U0001f98a"+strings.Repeat("a", 23)+"\U0001f436bcde")
+	assert.Equal(t, "\U0001f98a"+strings.Repeat("a", 23)+"bcde", actual)
+	assert.True(t, actualResults[0].Perfect)
+	assert.Equal(t, Match{Start: 0, End: 35}, actualResults[0].Match)
+}
+
+func TestPatchInvert(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+
+		Expected string
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Null case", "", "", ""},
+		{"Positions shift", "The quick brown fox jumps over the lazy dog.", "That quick brown fox jumped over a lazy dog.", "@@ -1,12 +1,11 @@\n Th\n-at\n+e\n  quick b\n@@ -21,17 +21,18 @@\n jump\n-ed\n+s\n  over \n-a\n+the\n  laz\n"},
+		{"Insertion", "abc", "abc123", "@@ -1,6 +1,3 @@\n abc\n-123\n"},
+	} {
+		patches := dmp.PatchMake(tc.Text1, tc.Text2)
+		inverted := dmp.PatchInvert(patches)
+		assert.Equal(t, tc.Expected, dmp.PatchToText(inverted), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		actual, actualApplies := dmp.PatchApply(inverted, tc.Text2)
+		assert.Equal(t, tc.Text1, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		for _, applied := range actualApplies {
+			assert.True(t, applied, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+		// The original patches are left alone.
+		assert.Equal(t, dmp.PatchToText(dmp.PatchMake(tc.Text1, tc.Text2)), dmp.PatchToText(patches), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Undo patches in either direction across the corpora, in bytes and in runes.
+	speedtest1, speedtest2 := speedtestTexts()
+	multilingual1, multilingual2 := multilingualTexts()
+	for _, unit := range []Unit{UnitByte, UnitRune} {
+		dmp.PatchUnit = unit
+		for _, texts := range [][2]string{{speedtest1, speedtest2}, {speedtest2, speedtest1}, {multilingual1, multilingual2}} {
+			patches := dmp.PatchMake(texts[0], texts[1])
+
+			patched, _ := dmp.PatchApply(patches, texts[0])
+			assert.Equal(t, texts[1], patched, fmt.Sprintf("Unit %s", unit))
+
+			actual, actualResults := dmp.PatchApplyResults(dmp.PatchInvert(patches), patched)
+			assert.Equal(t, texts[0], actual, fmt.Sprintf("Unit %s", unit))
+			for _, result := range actualResults {
+				assert.True(t, result.Applied, fmt.Sprintf("Unit %s", unit))
+				// Every patch is found right where it is expected.
+				assert.True(t, result.Perfect, fmt.Sprintf("Unit %s", unit))
+				assert.Equal(t, 0, result.Delta, fmt.Sprintf("Unit %s", unit))
+			}
+		}
+	}
+}
+
+func TestPatchMultilingual(t *testing.T) {
+	text1, text2 := multilingualTexts()
+
+	// Shift every patch by a different number of bytes and runes, and edit the text between patches.
+	prolog := "Prolog: 前書き 📜\n"
+	base := prolog + strings.Replace(text1, "vieux whisky", "vieux cognac", 1)
+	expected := prolog + strings.Replace(text2, "vieux whisky", "vieux cognac", 1)
+
+	dmp := New()
+	// Split up patches in either unit.
+	dmp.MatchMaxBits = 16
+
+	for _, unit := range []Unit{UnitByte, UnitRune} {
+		dmp.PatchUnit = unit
+		patches := dmp.PatchMake(text1, text2)
+
+		// The positions and lengths of every patch locate its text in the partially patched text, also once split up.
+		for _, ps := range [][]Patch{patches, dmp.PatchSplitMax(dmp.PatchDeepCopy(patches))} {
+			text := text1
+			for i, aPatch := range ps {
+				start := unitIndex(text, aPatch.Start2, unit)
+				end := unitIndex(text, aPatch.Start2+aPatch.Length1, unit)
+				assert.Equal(t, dmp.DiffText1(aPatch.diffs), text[start:end], fmt.Sprintf("Unit %s, patch #%d", unit, i))
+				assert.Equal(t, unitLen(dmp.DiffText2(aPatch.diffs), unit), aPatch.Length2, fmt.Sprintf("Unit %s, patch #%d", unit, i))
+
+				text = text[:start] + dmp.DiffText2(aPatch.diffs) + text[end:]
+			}
+			assert.Equal(t, text2, text, fmt.Sprintf("Unit %s", unit))
+		}
+
+		fromText, err := dmp.PatchFromText(dmp.PatchToText(patches))
+		assert.NoError(t, err, fmt.Sprintf("Unit %s", unit))
+		assert.Equal(t, patches, fromText, fmt.Sprintf("Unit %s", unit))
+
+		actual, actualApplies := dmp.PatchApply(fromText, text1)
+		assert.Equal(t, text2, actual, fmt.Sprintf("Unit %s", unit))
+		for _, applied := range actualApplies {
+			assert.True(t, applied, fmt.Sprintf("Unit %s", unit))
+		}
+
+		actual, actualApplies = dmp.PatchApply(patches, base)
+		assert.Equal(t, expected, actual, fmt.Sprintf("Unit %s", unit))
+		for _, applied := range actualApplies {
+			assert.True(t, applied, fmt.Sprintf("Unit %s", unit))
+		}
+	}
+
+	// Fuzzy matching of runes and grapheme clusters locates the patches in the drifted text just as well.
+	// The context of patches is cut at rune boundaries, so the clusters at its ends may not match and it takes some more context.
+	dmp.MatchMaxBits = 32
+	dmp.PatchMargin = 8
+	for _, unit := range []Unit{UnitRune, UnitGrapheme} {
+		dmp.PatchUnit = UnitRune
+		dmp.MatchUnit = unit
+
+		actual, actualApplies := dmp.PatchApply(dmp.PatchMake(text1, text2), base)
+		assert.Equal(t, expected, actual, fmt.Sprintf("Match unit %s", unit))
+		for _, applied := range actualApplies {
+			assert.True(t, applied, fmt.Sprintf("Match unit %s", unit))
+		}
+	}
+	dmp.MatchMaxBits = 16
+	dmp.MatchUnit = UnitByte
+	dmp.PatchMargin = 4
+
+	// Random edits of runes from the corpus.
+	corpus := []rune(text1 + text2)
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		runes1 := corpus[random.Intn(len(corpus)/2):]
+		runes1 = runes1[:random.Intn(len(runes1))]
+		runes2 := append([]rune{}, runes1...)
+		for j := 0; j < 5 && len(runes2) > 0; j++ {
+			k := random.Intn(len(runes2))
+			replacement := corpus[random.Intn(len(corpus)-10):][:random.Intn(10)]
+			runes2 = append(append(append([]rune{}, runes2[:k]...), replacement...), runes2[k+1:]...)
+		}
+		text1, text2 := string(runes1), string(runes2)
+
+		for _, unit := range []Unit{UnitByte, UnitRune} {
+			dmp.PatchUnit = unit
+
+			actual, _ := dmp.PatchApply(dmp.PatchMake(text1, text2), text1)
+			assert.Equal(t, text2, actual, fmt.Sprintf("Random case #%d, unit %s", i, unit))
+		}
+	}
+
+	// A diff of the drifted text may map the changes past the matched text or within a rune.
+	dmp.PatchUnit = UnitRune
+	actual, _ := dmp.PatchApply(dmp.PatchMake("\u00e9\U0001f98aa\U0001f98a\U0001f98a", "\u00e9a\U0001f98a"), "\u00e9\U0001f98a\U0001f98a\u00e9a\U0001f98a\U0001f98a")
+	assert.True(t, utf8.ValidString(actual), actual)
+
+	// Random edits of texts of few multibyte runes, applied to text which drifted by other random edits.
+	alphabet := []rune("a\u00e9\u65e5\U0001f98a")
+	randomRunes := func(n int) []rune {
+		runes := make([]rune, random.Intn(n+1))
+		for i := range runes {
+			runes[i] = alphabet[random.Intn(len(alphabet))]
+		}
+		return runes
+	}
+	randomEdit := func(runes []rune) string {
+		runes = append([]rune{}, runes...)
+		for i := random.Intn(3); i >= 0; i-- {
+			start := random.Intn(len(runes) + 1)
+			end := min(len(runes), start+random.Intn(4))
+			runes = append(runes[:start], append(randomRunes(3), runes[end:]...)...)
+		}
+		return string(runes)
+	}
+	for _, unit := range []Unit{UnitByte, UnitRune} {
+		dmp.PatchUnit = unit
+		for i := 0; i < 5000; i++ {
+			runes := randomRunes(20)
+			text1, text2, text3 := string(runes), randomEdit(runes), randomEdit(runes)
+
+			actual, _ := dmp.PatchApply(dmp.PatchMake(text1, text2), text3)
+			assert.True(t, utf8.ValidString(actual), fmt.Sprintf("Unit %s, texts %q, %q, %q", unit, text1, text2, text3))
+		}
+	}
+}
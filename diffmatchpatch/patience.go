@@ -0,0 +1,97 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"context"
+	"sort"
+)
+
+// diffPatience finds the differences between two rune slices using the patience diff algorithm.
+// Elements which occur exactly once in both slices are used as anchors. The longest sequence of anchors which is in order in both slices is kept and the gaps between the anchors are diffed recursively.
+// Falls back to diffBisect if there are no such anchors.
+// See Bram Cohen's description at https://bramcohen.livejournal.com/73318.html.
+func (dmp *DiffMatchPatch) diffPatience(ctx context.Context, text1, text2 []rune) []Diff {
+	anchors := patienceAnchors(text1, text2)
+	if len(anchors) == 0 {
+		return dmp.diffBisect(ctx, text1, text2)
+	}
+
+	var diffs []Diff
+	pointer1, pointer2 := 0, 0
+	for _, anchor := range anchors {
+		diffs = append(diffs, dmp.diffMainRunes(ctx, text1[pointer1:anchor[0]], text2[pointer2:anchor[1]], false)...)
+		diffs = append(diffs, Diff{DiffEqual, string(text1[anchor[0]])})
+		pointer1 = anchor[0] + 1
+		pointer2 = anchor[1] + 1
+	}
+	diffs = append(diffs, dmp.diffMainRunes(ctx, text1[pointer1:], text2[pointer2:], false)...)
+
+	return diffs
+}
+
+// patienceAnchors returns the index pairs of the longest sequence of elements which are unique in both slices and appear in the same order in both.
+func patienceAnchors[T comparable](text1, text2 []T) [][2]int {
+	type occurrence struct {
+		count1 int
+		count2 int
+		index2 int
+	}
+	occurrences := map[T]*occurrence{}
+	for _, r := range text1 {
+		o, ok := occurrences[r]
+		if !ok {
+			o = &occurrence{}
+			occurrences[r] = o
+		}
+		o.count1++
+	}
+	for i, r := range text2 {
+		if o, ok := occurrences[r]; ok {
+			o.count2++
+			o.index2 = i
+		}
+	}
+
+	// Unique common elements in the order of text1.
+	var pairs [][2]int
+	for i, r := range text1 {
+		if o := occurrences[r]; o.count1 == 1 && o.count2 == 1 {
+			pairs = append(pairs, [2]int{i, o.index2})
+		}
+	}
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	// Patience sorting: tails[k] is the pair ending the best increasing sequence of length k+1, and previous links each pair to its predecessor in that sequence.
+	tails := []int{}
+	previous := make([]int, len(pairs))
+	for i, pair := range pairs {
+		k := sort.Search(len(tails), func(k int) bool {
+			return pairs[tails[k]][1] > pair[1]
+		})
+		previous[i] = -1
+		if k > 0 {
+			previous[i] = tails[k-1]
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+
+	anchors := make([][2]int, len(tails))
+	for i, k := tails[len(tails)-1], len(tails)-1; k >= 0; i, k = previous[i], k-1 {
+		anchors[k] = pairs[i]
+	}
+
+	return anchors
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatienceAnchors(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+
+		Expected [][2]int
+	}
+
+	for i, tc := range []TestCase{
+		{"No common elements", "abc", "xyz", nil},
+		{"No unique elements", "aabb", "abab", nil},
+		{"In order", "abc", "xaybzc", [][2]int{{0, 1}, {1, 3}, {2, 5}}},
+		{"Crossing", "abcXdef", "defXabc", [][2]int{{4, 0}, {5, 1}, {6, 2}}},
+		{"Repeated elements are ignored", "axbxc", "cxbxa", [][2]int{{4, 0}}},
+	} {
+		actual := patienceAnchors([]rune(tc.Text1), []rune(tc.Text2))
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestDiffPatience(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+
+		Expected string
+	}
+
+	dmp := New()
+	dmp.DiffAlgorithm = AlgorithmPatience
+
+	frobnitz1 := "#include <stdio.h>\n\n// Frobs foo heartily\nint frobnitz(int foo)\n{\n    int i;\n    for(i = 0; i < 10; i++)\n    {\n        printf(\"Your answer is: \");\n        printf(\"%d\\n\", foo);\n    }\n}\n\nint fact(int n)\n{\n    if(n > 1)\n    {\n        return fact(n-1) * n;\n    }\n    return 1;\n}\n\nint main(int argc, char **argv)\n{\n    frobnitz(fact(10));\n}\n"
+	frobnitz2 := "#include <stdio.h>\n\nint fib(int n)\n{\n    if(n > 2)\n    {\n        return fib(n-1) + fib(n-2);\n    }\n    return 1;\n}\n\n// Frobs foo heartily\nint frobnitz(int foo)\n{\n    int i;\n    for(i = 0; i < 10; i++)\n    {\n        printf(\"%d\\n\", foo);\n    }\n}\n\nint main(int argc, char **argv)\n{\n    frobnitz(fib(10));\n}\n"
+
+	// Expected outputs were produced by "git diff --no-index --patience --no-indent-heuristic".
+	for i, tc := range []TestCase{
+		{"Moved function", frobnitz1, frobnitz2, "@@ -1,26 +1,25 @@\n #include <stdio.h>\n \n+int fib(int n)\n+{\n+    if(n > 2)\n+    {\n+        return fib(n-1) + fib(n-2);\n+    }\n+    return 1;\n+}\n+\n // Frobs foo heartily\n int frobnitz(int foo)\n {\n     int i;\n     for(i = 0; i < 10; i++)\n     {\n-        printf(\"Your answer is: \");\n         printf(\"%d\\n\", foo);\n     }\n }\n \n-int fact(int n)\n-{\n-    if(n > 1)\n-    {\n-        return fact(n-1) * n;\n-    }\n-    return 1;\n-}\n-\n int main(int argc, char **argv)\n {\n-    frobnitz(fact(10));\n+    frobnitz(fib(10));\n }\n"},
+		{"Inserted function", "void func1() {\n    x += 1\n}\n\nvoid func2() {\n    x += 2\n}\n", "void func1() {\n    x += 1\n}\n\nvoid functhreehalves() {\n    x += 1.5\n}\n\nvoid func2() {\n    x += 2\n}\n", "@@ -2,6 +2,10 @@\n     x += 1\n }\n \n+void functhreehalves() {\n+    x += 1.5\n+}\n+\n void func2() {\n     x += 2\n }\n"},
+		{"Unique lines win", "u12\nreturn\nu3\nreturn\nreturn\nu1\n}\n{\nx = 1\nu10\nu10\n{\n", "u12\nm1\nu3\n}\nreturn\nreturn\nu1\n{\nx = 1\nu10\nu10\n", "@@ -1,12 +1,11 @@\n u12\n-return\n+m1\n u3\n-return\n-return\n-u1\n }\n+return\n+return\n+u1\n {\n x = 1\n u10\n u10\n-{\n"},
+	} {
+		var actual []string
+		for _, hunk := range dmp.DiffToUnifiedHunks(dmp.diffLinesExact(tc.Text1, tc.Text2)) {
+			actual = append(actual, hunk.String())
+		}
+		assert.Equal(t, tc.Expected, strings.Join(actual, ""), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Character mode.
+	assert.Equal(t, []Diff{{DiffDelete, "abcX"}, {DiffEqual, "def"}, {DiffInsert, "Xabc"}}, dmp.DiffMain("abcXdef", "defXabc", false))
+	assert.Equal(t, []Diff{{DiffEqual, "a"}, {DiffDelete, "x"}, {DiffInsert, "y"}, {DiffEqual, "b"}}, dmp.DiffMain("axb", "ayb", false))
+
+	// Line mode with character refinement.
+	text1, text2 := speedtestTexts()
+	diffs := dmp.DiffMain(text1, text2, true)
+	assert.Equal(t, text1, dmp.DiffText1(diffs))
+	assert.Equal(t, text2, dmp.DiffText2(diffs))
+}
+
+func BenchmarkDiffPatienceLarge(b *testing.B) {
+	s1, s2 := speedtestTexts()
+
+	dmp := New()
+	dmp.DiffAlgorithm = AlgorithmPatience
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		dmp.DiffMain(s1, s2, true)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"strconv"
+	"strings"
+)
+
+// diffRow is a row of a rendered line-based diff. Line numbers count from 1, a line number of 0 means that the row has no line of that text.
+// Type tells if the row holds an unchanged, a deleted or an inserted line. A side by side row which holds both a deleted and an inserted line counts as an insertion.
+// Words1 and Words2 are the lines without their newline as a word-based diff, of which only the equalities and the changes of the line's own text belong to it.
+type diffRow struct {
+	Type   Operation
+	Line1  int
+	Line2  int
+	Text1  string
+	Text2  string
+	Words1 []Diff
+	Words2 []Diff
+}
+
+// diffRows splits a diff into the rows of a rendered diff.
+// Side by side, a row of changes holds a deleted line, an inserted line or both. Otherwise every row holds one line, and all deleted lines of a change come before the inserted ones.
+// Deleted and inserted lines are paired up in order, and the words which changed within them are computed by DiffMainWords.
+func (dmp *DiffMatchPatch) diffRows(diffs []Diff, sideBySide bool) []diffRow {
+	lines := dmp.diffToLines(diffs)
+	var rows []diffRow
+	line1, line2 := 0, 0
+	for pointer := 0; pointer < len(lines); {
+		if lines[pointer].Type == DiffEqual {
+			line1++
+			line2++
+			text := lines[pointer].Text
+			words := diffRowWords(text)
+			rows = append(rows, diffRow{DiffEqual, line1, line2, text, text, words, words})
+			pointer++
+			continue
+		}
+
+		var deletions, insertions []string
+		for ; pointer < len(lines) && lines[pointer].Type != DiffEqual; pointer++ {
+			if lines[pointer].Type == DiffDelete {
+				deletions = append(deletions, lines[pointer].Text)
+			} else {
+				insertions = append(insertions, lines[pointer].Text)
+			}
+		}
+
+		// Pair up deleted and inserted lines and find the words which changed.
+		words1 := make([][]Diff, len(deletions))
+		words2 := make([][]Diff, len(insertions))
+		for i := range deletions {
+			if i >= len(insertions) {
+				words1[i] = diffRowWords(deletions[i])
+				continue
+			}
+			words := dmp.DiffMainWords(strings.TrimSuffix(deletions[i], "\n"), strings.TrimSuffix(insertions[i], "\n"))
+			words1[i] = words
+			words2[i] = words
+		}
+		for i := len(deletions); i < len(insertions); i++ {
+			words2[i] = diffRowWords(insertions[i])
+		}
+
+		if sideBySide {
+			for i := 0; i < len(deletions) || i < len(insertions); i++ {
+				row := diffRow{}
+				if i < len(deletions) {
+					line1++
+					row.Type = DiffDelete
+					row.Line1 = line1
+					row.Text1 = deletions[i]
+					row.Words1 = words1[i]
+				}
+				if i < len(insertions) {
+					line2++
+					row.Type = DiffInsert
+					row.Line2 = line2
+					row.Text2 = insertions[i]
+					row.Words2 = words2[i]
+				}
+				rows = append(rows, row)
+			}
+			continue
+		}
+		for i, text := range deletions {
+			line1++
+			rows = append(rows, diffRow{Type: DiffDelete, Line1: line1, Text1: text, Words1: words1[i]})
+		}
+		for i, text := range insertions {
+			line2++
+			rows = append(rows, diffRow{Type: DiffInsert, Line2: line2, Text2: text, Words2: words2[i]})
+		}
+	}
+
+	return rows
+}
+
+// diffRowWords returns the words of a line which has no counterpart, i.e. the whole line without its newline.
+func diffRowWords(line string) []Diff {
+	return []Diff{{DiffEqual, strings.TrimSuffix(line, "\n")}}
+}
+
+// diffFoldRows tells for every row whether it is folded, which are the unchanged rows that are more than context rows away from a change.
+func diffFoldRows(rows []diffRow, context int) []bool {
+	folded := make([]bool, len(rows))
+	context = max(0, context)
+	for start := 0; start < len(rows); {
+		if rows[start].Type != DiffEqual {
+			start++
+			continue
+		}
+		end := start
+		for end < len(rows) && rows[end].Type == DiffEqual {
+			end++
+		}
+		// Keep the context next to changes.
+		first, last := start, end
+		if start != 0 {
+			first += context
+		}
+		if end != len(rows) {
+			last -= context
+		}
+		for i := first; i < last; i++ {
+			folded[i] = true
+		}
+		start = end
+	}
+
+	return folded
+}
+
+// diffRowNumber formats a line number of a row, which is empty for a missing line.
+func diffRowNumber(line int) string {
+	if line == 0 {
+		return ""
+	}
+	return strconv.Itoa(line)
+}
@@ -0,0 +1,230 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"context"
+)
+
+// SequenceEdit represents one diff operation between two sequences a and b by the index ranges it covers.
+// Equalities cover a[Start1:End1] and the equal b[Start2:End2]. Deletions cover a[Start1:End1] and insertions cover b[Start2:End2], while their range in the other sequence is empty and marks the position at which they take place.
+type SequenceEdit struct {
+	Type   Operation
+	Start1 int
+	End1   int
+	Start2 int
+	End2   int
+}
+
+// DiffSequence finds the differences between two slices of comparable elements.
+// The settings of dmp select the algorithm and the timeout, and the edits are returned in order, covering both slices from start to end.
+// Deletions always come before insertions between two equalities.
+func DiffSequence[T comparable](dmp *DiffMatchPatch, a, b []T) []SequenceEdit {
+	ids := map[T]int{}
+	intern := func(seq []T) []int {
+		interned := make([]int, len(seq))
+		for i, e := range seq {
+			id, ok := ids[e]
+			if !ok {
+				id = len(ids)
+				ids[e] = id
+			}
+			interned[i] = id
+		}
+		return interned
+	}
+
+	return dmp.diffSequence(intern(a), intern(b))
+}
+
+// DiffSequenceFunc finds the differences between two slices whose elements are compared by the given functions.
+// Elements which are equal must have the same hash, while elements with the same hash are told apart by equal.
+// Otherwise it behaves like DiffSequence.
+func DiffSequenceFunc[T any](dmp *DiffMatchPatch, a, b []T, hash func(T) uint64, equal func(x, y T) bool) []SequenceEdit {
+	// Representatives of every element seen so far, by the hash of the element. An element's position in the slice of all representatives is its id.
+	var representatives []T
+	buckets := map[uint64][]int{}
+	intern := func(seq []T) []int {
+		interned := make([]int, len(seq))
+		for i, e := range seq {
+			h := hash(e)
+			id := -1
+			for _, candidate := range buckets[h] {
+				if equal(representatives[candidate], e) {
+					id = candidate
+					break
+				}
+			}
+			if id == -1 {
+				id = len(representatives)
+				representatives = append(representatives, e)
+				buckets[h] = append(buckets[h], id)
+			}
+			interned[i] = id
+		}
+		return interned
+	}
+
+	return dmp.diffSequence(intern(a), intern(b))
+}
+
+// diffSequence finds the differences between two sequences of element ids.
+func (dmp *DiffMatchPatch) diffSequence(seq1, seq2 []int) []SequenceEdit {
+	ctx := context.Background()
+	if dmp.DiffTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, dmp.DiffTimeout)
+		defer cancel()
+	}
+
+	edits := dmp.diffSequenceMain(ctx, seq1, seq2, 0, 0, nil)
+
+	return sequenceEditsMerge(edits, len(seq1), len(seq2))
+}
+
+// diffSequenceMain appends the edits between seq1 and seq2 to edits, where seq1 starts at offset1 and seq2 at offset2 of the complete sequences.
+func (dmp *DiffMatchPatch) diffSequenceMain(ctx context.Context, seq1, seq2 []int, offset1, offset2 int, edits []SequenceEdit) []SequenceEdit {
+	// Trim off common prefix (speedup).
+	prefix := 0
+	for prefix < len(seq1) && prefix < len(seq2) && seq1[prefix] == seq2[prefix] {
+		prefix++
+	}
+	if prefix > 0 {
+		edits = append(edits, SequenceEdit{DiffEqual, offset1, offset1 + prefix, offset2, offset2 + prefix})
+	}
+	seq1 = seq1[prefix:]
+	seq2 = seq2[prefix:]
+	offset1 += prefix
+	offset2 += prefix
+
+	// Trim off common suffix (speedup).
+	suffix := 0
+	for suffix < len(seq1) && suffix < len(seq2) && seq1[len(seq1)-suffix-1] == seq2[len(seq2)-suffix-1] {
+		suffix++
+	}
+	seq1 = seq1[:len(seq1)-suffix]
+	seq2 = seq2[:len(seq2)-suffix]
+
+	// Compute the diff on the middle block.
+	edits = dmp.diffSequenceCompute(ctx, seq1, seq2, offset1, offset2, edits)
+
+	// Restore the suffix.
+	if suffix > 0 {
+		offset1 += len(seq1)
+		offset2 += len(seq2)
+		edits = append(edits, SequenceEdit{DiffEqual, offset1, offset1 + suffix, offset2, offset2 + suffix})
+	}
+
+	return edits
+}
+
+// diffSequenceCompute appends the edits between seq1 and seq2 to edits. Assumes that the sequences do not have any common prefix or suffix.
+func (dmp *DiffMatchPatch) diffSequenceCompute(ctx context.Context, seq1, seq2 []int, offset1, offset2 int, edits []SequenceEdit) []SequenceEdit {
+	if len(seq1) == 0 && len(seq2) == 0 {
+		return edits
+	} else if len(seq1) == 0 || len(seq2) == 0 || ctx.Err() != nil {
+		// Nothing in common, or no time left to find out.
+		return sequenceEditsReplace(seq1, seq2, offset1, offset2, edits)
+	} else if len(seq1) == 1 || len(seq2) == 1 {
+		// Single element, which is either found in the other sequence or has nothing in common with it (speedup).
+		for i, e := range seq2 {
+			if len(seq1) == 1 && e == seq1[0] {
+				edits = sequenceEditsReplace(nil, seq2[:i], offset1, offset2, edits)
+				edits = append(edits, SequenceEdit{DiffEqual, offset1, offset1 + 1, offset2 + i, offset2 + i + 1})
+				return sequenceEditsReplace(nil, seq2[i+1:], offset1+1, offset2+i+1, edits)
+			}
+		}
+		for i, e := range seq1 {
+			if len(seq2) == 1 && e == seq2[0] {
+				edits = sequenceEditsReplace(seq1[:i], nil, offset1, offset2, edits)
+				edits = append(edits, SequenceEdit{DiffEqual, offset1 + i, offset1 + i + 1, offset2, offset2 + 1})
+				return sequenceEditsReplace(seq1[i+1:], nil, offset1+i+1, offset2+1, edits)
+			}
+		}
+		return sequenceEditsReplace(seq1, seq2, offset1, offset2, edits)
+	}
+
+	switch dmp.DiffAlgorithm {
+	case AlgorithmPatience:
+		anchors := patienceAnchors(seq1, seq2)
+		if len(anchors) == 0 {
+			break
+		}
+		pointer1, pointer2 := 0, 0
+		for _, anchor := range anchors {
+			edits = dmp.diffSequenceMain(ctx, seq1[pointer1:anchor[0]], seq2[pointer2:anchor[1]], offset1+pointer1, offset2+pointer2, edits)
+			edits = append(edits, SequenceEdit{DiffEqual, offset1 + anchor[0], offset1 + anchor[0] + 1, offset2 + anchor[1], offset2 + anchor[1] + 1})
+			pointer1 = anchor[0] + 1
+			pointer2 = anchor[1] + 1
+		}
+		return dmp.diffSequenceMain(ctx, seq1[pointer1:], seq2[pointer2:], offset1+pointer1, offset2+pointer2, edits)
+	case AlgorithmHistogram:
+		lcs, ok := histogramLCS(seq1, seq2)
+		if !ok {
+			break
+		} else if lcs.begin1 == lcs.end1 {
+			return sequenceEditsReplace(seq1, seq2, offset1, offset2, edits)
+		}
+		edits = dmp.diffSequenceMain(ctx, seq1[:lcs.begin1], seq2[:lcs.begin2], offset1, offset2, edits)
+		edits = append(edits, SequenceEdit{DiffEqual, offset1 + lcs.begin1, offset1 + lcs.end1, offset2 + lcs.begin2, offset2 + lcs.end2})
+		return dmp.diffSequenceMain(ctx, seq1[lcs.end1:], seq2[lcs.end2:], offset1+lcs.end1, offset2+lcs.end2, edits)
+	}
+
+	x, y, ok := bisectMiddleSnake(ctx, seq1, seq2)
+	if !ok {
+		return sequenceEditsReplace(seq1, seq2, offset1, offset2, edits)
+	}
+	edits = dmp.diffSequenceMain(ctx, seq1[:x], seq2[:y], offset1, offset2, edits)
+	return dmp.diffSequenceMain(ctx, seq1[x:], seq2[y:], offset1+x, offset2+y, edits)
+}
+
+// sequenceEditsReplace appends the deletion of all of seq1 and the insertion of all of seq2 to edits.
+func sequenceEditsReplace(seq1, seq2 []int, offset1, offset2 int, edits []SequenceEdit) []SequenceEdit {
+	if len(seq1) > 0 {
+		edits = append(edits, SequenceEdit{DiffDelete, offset1, offset1 + len(seq1), offset2, offset2})
+	}
+	if len(seq2) > 0 {
+		edits = append(edits, SequenceEdit{DiffInsert, offset1 + len(seq1), offset1 + len(seq1), offset2, offset2 + len(seq2)})
+	}
+	return edits
+}
+
+// sequenceEditsMerge joins adjacent equalities, and turns the deletions and insertions between two equalities into at most one deletion followed by at most one insertion.
+// The edits have to cover sequences of the lengths len1 and len2.
+func sequenceEditsMerge(edits []SequenceEdit, len1, len2 int) []SequenceEdit {
+	var merged []SequenceEdit
+	// Start of the current run of deletions and insertions.
+	start1, start2 := 0, 0
+
+	flush := func(end1, end2 int) {
+		if start1 < end1 {
+			merged = append(merged, SequenceEdit{DiffDelete, start1, end1, start2, start2})
+		}
+		if start2 < end2 {
+			merged = append(merged, SequenceEdit{DiffInsert, end1, end1, start2, end2})
+		}
+	}
+
+	for _, edit := range edits {
+		if edit.Type != DiffEqual {
+			continue
+		}
+		flush(edit.Start1, edit.Start2)
+		if n := len(merged); n > 0 && merged[n-1].Type == DiffEqual && merged[n-1].End1 == edit.Start1 {
+			merged[n-1].End1 = edit.End1
+			merged[n-1].End2 = edit.End2
+		} else {
+			merged = append(merged, edit)
+		}
+		start1, start2 = edit.End1, edit.End2
+	}
+	flush(len1, len2)
+
+	return merged
+}
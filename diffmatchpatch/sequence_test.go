@@ -0,0 +1,198 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sequenceRebuild rebuilds both sequences from the edits, and returns the number of deleted and inserted elements.
+func sequenceRebuild[T any](a, b []T, edits []SequenceEdit) ([]T, []T, int) {
+	var a2, b2 []T
+	changes := 0
+	for _, edit := range edits {
+		switch edit.Type {
+		case DiffEqual:
+			a2 = append(a2, a[edit.Start1:edit.End1]...)
+			b2 = append(b2, b[edit.Start2:edit.End2]...)
+		case DiffDelete:
+			a2 = append(a2, a[edit.Start1:edit.End1]...)
+			changes += edit.End1 - edit.Start1
+		case DiffInsert:
+			b2 = append(b2, b[edit.Start2:edit.End2]...)
+			changes += edit.End2 - edit.Start2
+		}
+	}
+	return a2, b2, changes
+}
+
+// lcsLength computes the length of the longest common subsequence by dynamic programming.
+func lcsLength(a, b []int) int {
+	lengths := make([]int, len(b)+1)
+	for i := range a {
+		previous := 0
+		for j := range b {
+			current := lengths[j+1]
+			if a[i] == b[j] {
+				lengths[j+1] = previous + 1
+			} else if lengths[j] > lengths[j+1] {
+				lengths[j+1] = lengths[j]
+			}
+			previous = current
+		}
+	}
+	return lengths[len(b)]
+}
+
+func TestDiffSequence(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		A []string
+		B []string
+
+		Expected []SequenceEdit
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Null case", nil, nil, nil},
+		{"Equality", []string{"a", "b"}, []string{"a", "b"}, []SequenceEdit{{DiffEqual, 0, 2, 0, 2}}},
+		{"Insertion", []string{"a", "c"}, []string{"a", "b", "c"}, []SequenceEdit{{DiffEqual, 0, 1, 0, 1}, {DiffInsert, 1, 1, 1, 2}, {DiffEqual, 1, 2, 2, 3}}},
+		{"Deletion", []string{"a", "b", "c"}, []string{"a", "c"}, []SequenceEdit{{DiffEqual, 0, 1, 0, 1}, {DiffDelete, 1, 2, 1, 1}, {DiffEqual, 2, 3, 1, 2}}},
+		{"Replacement", []string{"a", "b", "c"}, []string{"a", "x", "y", "c"}, []SequenceEdit{{DiffEqual, 0, 1, 0, 1}, {DiffDelete, 1, 2, 1, 1}, {DiffInsert, 2, 2, 1, 3}, {DiffEqual, 2, 3, 3, 4}}},
+		{"Everything", []string{"a"}, []string{"b"}, []SequenceEdit{{DiffDelete, 0, 1, 0, 0}, {DiffInsert, 1, 1, 0, 1}}},
+		{"Multiple edits", []string{"x", "a", "b", "c", "y"}, []string{"a", "z", "c"}, []SequenceEdit{{DiffDelete, 0, 1, 0, 0}, {DiffEqual, 1, 2, 0, 1}, {DiffDelete, 2, 3, 1, 1}, {DiffInsert, 3, 3, 1, 2}, {DiffEqual, 3, 4, 2, 3}, {DiffDelete, 4, 5, 3, 3}}},
+	} {
+		for _, algorithm := range []Algorithm{AlgorithmBisect, AlgorithmPatience, AlgorithmHistogram} {
+			dmp.DiffAlgorithm = algorithm
+
+			actual := DiffSequence(dmp, tc.A, tc.B)
+			assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s, %s", i, tc.Name, algorithm))
+		}
+	}
+
+	// Random sequences, where bisection has to find a shortest edit script.
+	dmp.DiffTimeout = 0
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		a := make([]int, random.Intn(40))
+		for j := range a {
+			a[j] = random.Intn(5)
+		}
+		b := make([]int, random.Intn(40))
+		for j := range b {
+			b[j] = random.Intn(5)
+		}
+
+		for _, algorithm := range []Algorithm{AlgorithmBisect, AlgorithmPatience, AlgorithmHistogram} {
+			dmp.DiffAlgorithm = algorithm
+
+			edits := DiffSequence(dmp, a, b)
+			a2, b2, changes := sequenceRebuild(a, b, edits)
+			assert.Equal(t, a, append([]int{}, a2...), fmt.Sprintf("Random case #%d, %s", i, algorithm))
+			assert.Equal(t, b, append([]int{}, b2...), fmt.Sprintf("Random case #%d, %s", i, algorithm))
+			if algorithm == AlgorithmBisect {
+				assert.Equal(t, len(a)+len(b)-2*lcsLength(a, b), changes, fmt.Sprintf("Random case #%d, %s", i, algorithm))
+			}
+
+			// Edits are merged.
+			for j := 1; j < len(edits); j++ {
+				assert.False(t, edits[j-1].Type == edits[j].Type, fmt.Sprintf("Random case #%d, %s", i, algorithm))
+				assert.False(t, edits[j-1].Type == DiffInsert && edits[j].Type == DiffDelete, fmt.Sprintf("Random case #%d, %s", i, algorithm))
+			}
+		}
+	}
+}
+
+func TestDiffSequenceLarge(t *testing.T) {
+	dmp := New()
+
+	// More distinct elements than there are Unicode code points.
+	a := make([]int, 1200000)
+	for i := range a {
+		a[i] = i
+	}
+	b := append([]int{}, a...)
+	b[600000] = -1
+
+	assert.Equal(t, []SequenceEdit{
+		{DiffEqual, 0, 600000, 0, 600000},
+		{DiffDelete, 600000, 600001, 600000, 600000},
+		{DiffInsert, 600001, 600001, 600000, 600001},
+		{DiffEqual, 600001, 1200000, 600001, 1200000},
+	}, DiffSequence(dmp, a, b))
+
+	// Surrogate code points are elements like any other.
+	assert.Equal(t, []SequenceEdit{
+		{DiffEqual, 0, 1, 0, 1},
+		{DiffDelete, 1, 2, 1, 1},
+		{DiffInsert, 2, 2, 1, 2},
+	}, DiffSequence(dmp, []rune{'a', 0xD800}, []rune{'a', 0xDC00}))
+}
+
+func TestDiffSequenceFunc(t *testing.T) {
+	type record struct {
+		Key   string
+		Value int
+	}
+
+	a := []record{{"a", 1}, {"b", 2}, {"c", 3}, {"d", 4}}
+	b := []record{{"A", 1}, {"b", 2}, {"C", 30}, {"d", 4}}
+
+	dmp := New()
+	equal := func(x, y record) bool {
+		return strings.EqualFold(x.Key, y.Key)
+	}
+
+	for _, hash := range []func(record) uint64{
+		func(r record) uint64 {
+			return uint64(strings.ToLower(r.Key)[0])
+		},
+		// Every element collides.
+		func(r record) uint64 {
+			return 0
+		},
+	} {
+		assert.Equal(t, []SequenceEdit{{DiffEqual, 0, 4, 0, 4}}, DiffSequenceFunc(dmp, a, b, hash, equal))
+	}
+
+	equal = func(x, y record) bool {
+		return x.Value == y.Value
+	}
+	hash := func(r record) uint64 {
+		return uint64(r.Value % 2)
+	}
+	assert.Equal(t, []SequenceEdit{
+		{DiffEqual, 0, 2, 0, 2},
+		{DiffDelete, 2, 3, 2, 2},
+		{DiffInsert, 3, 3, 2, 3},
+		{DiffEqual, 3, 4, 3, 4},
+	}, DiffSequenceFunc(dmp, a, b, hash, equal))
+}
+
+func BenchmarkDiffSequenceLarge(b *testing.B) {
+	s1, s2 := speedtestTexts()
+	lines1 := strings.SplitAfter(s1, "\n")
+	lines2 := strings.SplitAfter(s2, "\n")
+
+	dmp := New()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		DiffSequence(dmp, lines1, lines2)
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// Number of cells between tab stops in side by side diffs.
+const terminalTabWidth = 8
+
+// Marks between the columns of a side by side diff, as used by "diff -y".
+const (
+	sideBySideEqual  = " "
+	sideBySideChange = "|"
+	sideBySideDelete = "<"
+	sideBySideInsert = ">"
+)
+
+// DiffSideBySideTerminal renders a line-based diff for terminals in two columns of the given width, the old text on the left and the new text on the right.
+// Deleted and inserted lines are paired up in rows, and the words which changed within them are highlighted. The mark between the columns tells whether a row was changed ("|"), deleted ("<") or inserted (">").
+// Lines which are wider than the columns are wrapped between words where possible, counting East Asian wide characters and emoji as two cells. Tabs are expanded to tab stops every 8 cells.
+// Colors, line numbers, whitespace markers and folding work like for DiffTerminal.
+func (dmp *DiffMatchPatch) DiffSideBySideTerminal(diffs []Diff, width int) string {
+	rows := dmp.diffRows(diffs, true)
+	folded := diffFoldRows(rows, dmp.UnifiedContext)
+	colors := terminalColorsFor(dmp.TerminalTheme, dmp.TerminalBackground)
+	width = max(width, 2)
+
+	// Width of the line numbers, 0 for no gutter.
+	numberWidth := 0
+	if dmp.TerminalLineNumbers {
+		for _, row := range rows {
+			numberWidth = max(numberWidth, len(strconv.Itoa(max(row.Line1, row.Line2))))
+		}
+	}
+
+	var buff bytes.Buffer
+	for i, row := range rows {
+		if folded[i] {
+			if i == 0 || !folded[i-1] {
+				_, _ = buff.WriteString(terminalPaint(colors.Fold, "...") + "\n")
+			}
+			continue
+		}
+
+		mark, markColor := sideBySideEqual, ""
+		var lines1, lines2 []string
+		var widths1 []int
+		switch {
+		case row.Line1 != 0 && row.Line2 != 0 && row.Type != DiffEqual:
+			mark, markColor = sideBySideChange, colors.Gutter
+		case row.Line2 == 0:
+			mark, markColor = sideBySideDelete, colors.Delete
+		case row.Line1 == 0:
+			mark, markColor = sideBySideInsert, colors.Insert
+		}
+		if row.Line1 != 0 {
+			lineColor, wordColor := "", ""
+			if row.Type != DiffEqual {
+				lineColor, wordColor = colors.Delete, colors.DeleteWord
+			}
+			lines1, widths1 = dmp.terminalWrap(row.Text1, row.Words1, DiffDelete, lineColor, wordColor, colors, width)
+		}
+		if row.Line2 != 0 {
+			lineColor, wordColor := "", ""
+			if row.Type != DiffEqual {
+				lineColor, wordColor = colors.Insert, colors.InsertWord
+			}
+			lines2, _ = dmp.terminalWrap(row.Text2, row.Words2, DiffInsert, lineColor, wordColor, colors, width)
+		}
+
+		for j := 0; j < len(lines1) || j < len(lines2); j++ {
+			var line bytes.Buffer
+			number1, number2 := "", ""
+			if j == 0 {
+				number1, number2 = diffRowNumber(row.Line1), diffRowNumber(row.Line2)
+			} else {
+				mark, markColor = sideBySideEqual, ""
+			}
+			if numberWidth > 0 {
+				_, _ = line.WriteString(strings.Repeat(" ", numberWidth-len(number1)) + terminalPaint(colors.Gutter, number1) + " ")
+			}
+			if j < len(lines1) {
+				_, _ = line.WriteString(lines1[j] + strings.Repeat(" ", max(0, width-widths1[j])))
+			} else {
+				_, _ = line.WriteString(strings.Repeat(" ", width))
+			}
+			_, _ = line.WriteString(" " + terminalPaint(markColor, mark) + " ")
+			if numberWidth > 0 {
+				_, _ = line.WriteString(strings.Repeat(" ", numberWidth-len(number2)) + terminalPaint(colors.Gutter, number2) + " ")
+			}
+			if j < len(lines2) {
+				_, _ = line.WriteString(lines2[j])
+			}
+			_, _ = buff.WriteString(strings.TrimRight(line.String(), " ") + "\n")
+		}
+	}
+
+	return buff.String()
+}
+
+// terminalWrap renders a line of one text for a side by side diff, given a word-based diff of its text without the newline, and wraps it into lines of at most width cells.
+// The parts of the diff which only belong to the other text are left out, and the ones of the given type are colored as changed.
+// It returns the wrapped lines along with their widths.
+func (dmp *DiffMatchPatch) terminalWrap(line string, words []Diff, op Operation, lineColor, wordColor string, colors terminalColors, width int) ([]string, []int) {
+	w := &terminalWrapper{width: width}
+	for _, aDiff := range words {
+		color := lineColor
+		if aDiff.Type == op {
+			color = wordColor
+		} else if aDiff.Type != DiffEqual {
+			continue
+		}
+		markerColor := terminalMarkerColor(color, colors)
+
+		for _, word := range terminalWords(aDiff.Text) {
+			// Words which fit into a line are not split up.
+			graphemes := TokenizeGraphemes(word)
+			wordWidth := 0
+			for _, grapheme := range graphemes {
+				wordWidth += graphemeWidth(grapheme)
+			}
+			if strings.TrimSpace(word) != "" {
+				w.fit(wordWidth)
+			}
+
+			for _, grapheme := range graphemes {
+				switch {
+				case grapheme == "\t":
+					if w.cells >= w.width {
+						w.wrap()
+					}
+					cells := min(terminalTabWidth-w.cells%terminalTabWidth, w.width-w.cells)
+					if dmp.TerminalWhitespace {
+						w.write(terminalTabMarker, markerColor, 1)
+						cells--
+					}
+					w.write(strings.Repeat(" ", cells), color, cells)
+				case grapheme == " " && !dmp.TerminalWhitespace && w.cells >= w.width:
+					// A space at the end of a line turns into the line break.
+					continue
+				case dmp.TerminalWhitespace && (grapheme == " " || grapheme == "\r"):
+					w.write(terminalWhitespace.Replace(grapheme), markerColor, 1)
+				case graphemeClassOf([]rune(grapheme)[0]) == graphemeControl || grapheme == "\r":
+					// Control characters would garble the columns.
+					continue
+				default:
+					w.write(grapheme, color, graphemeWidth(grapheme))
+				}
+			}
+		}
+	}
+
+	if !strings.HasSuffix(line, "\n") {
+		w.wrap()
+		for _, word := range terminalWords(noNewlineMarker) {
+			if word == " " && w.cells >= w.width {
+				continue
+			}
+			// Words which fit into a line are not split up, the others are split like the words of the text.
+			w.fit(len(word))
+			for _, char := range strings.Split(word, "") {
+				w.write(char, colors.Gutter, 1)
+			}
+		}
+	} else if dmp.TerminalWhitespace {
+		w.write(terminalNewlineMarker, terminalMarkerColor(lineColor, colors), 1)
+	}
+	w.wrap()
+
+	return w.lines, w.widths
+}
+
+// terminalWords splits text into the words which are kept together when wrapping lines, i.e. runs of whitespace, wide characters such as ideographs, and runs of any other tokens of TokenizeWords.
+func terminalWords(text string) []string {
+	var words []string
+	joinable := func(token string) bool {
+		for _, r := range token {
+			if runeWidth(r) == 2 {
+				return false
+			}
+		}
+		return strings.TrimSpace(token) != ""
+	}
+	for _, token := range TokenizeWords(text) {
+		if n := len(words); n > 0 && joinable(words[n-1]) && joinable(token) {
+			words[n-1] += token
+		} else {
+			words = append(words, token)
+		}
+	}
+	return words
+}
+
+// terminalWrapper collects colored text into lines of a given width.
+type terminalWrapper struct {
+	width int
+
+	lines  []string
+	widths []int
+
+	// The current line, and the text of it which is yet to be colored.
+	line  bytes.Buffer
+	run   bytes.Buffer
+	color string
+	cells int
+}
+
+// write appends text which takes up the given number of cells in the given color, starting a new line if it does not fit into the current one.
+func (w *terminalWrapper) write(text, color string, cells int) {
+	if w.cells > 0 && w.cells+cells > w.width {
+		w.wrap()
+	}
+	if color != w.color {
+		w.flush()
+		w.color = color
+	}
+	_, _ = w.run.WriteString(text)
+	w.cells += cells
+}
+
+// fit starts a new line if a word of the given width does not fit into the current line, but would fit into an empty one.
+func (w *terminalWrapper) fit(cells int) {
+	if w.cells > 0 && w.cells+cells > w.width && cells <= w.width {
+		w.wrap()
+	}
+}
+
+// flush colors the text written in the current color.
+func (w *terminalWrapper) flush() {
+	_, _ = w.line.WriteString(terminalPaint(w.color, w.run.String()))
+	w.run.Reset()
+}
+
+// wrap ends the current line, unless it is empty and not the first line.
+func (w *terminalWrapper) wrap() {
+	w.flush()
+	if w.cells == 0 && w.line.Len() == 0 && len(w.lines) > 0 {
+		return
+	}
+	w.lines = append(w.lines, w.line.String())
+	w.widths = append(w.widths, w.cells)
+	w.line.Reset()
+	w.cells = 0
+}
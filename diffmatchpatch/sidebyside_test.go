@@ -0,0 +1,122 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffSideBySideTerminal(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Theme       TerminalTheme
+		LineNumbers bool
+		Whitespace  bool
+		Context     int
+		Width       int
+
+		Text1 string
+		Text2 string
+
+		Expected []string
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Null case", TerminalThemeNone, true, false, 3, 10, "", "", nil},
+		{
+			"Changed, deleted and inserted rows", TerminalThemeNone, false, false, 3, 5,
+			"same\nold\ngone\nmid\n", "same\nnew\nmid\nadded\n",
+			[]string{
+				"same    same",
+				"old   | new",
+				"gone  <",
+				"mid     mid",
+				"      > added",
+			},
+		},
+		{
+			"Folded with line numbers", TerminalThemeNone, true, false, 0, 3,
+			"a\nb\nc\n", "a\nB\nc\n",
+			[]string{
+				"...",
+				"2 b   | 2 B",
+				"...",
+			},
+		},
+		{
+			"Wrapped wide characters", TerminalThemeNone, false, false, 3, 3,
+			"\u6f22\u5b57\n", "\u6f22\u5b57x\n",
+			[]string{
+				"\u6f22  | \u6f22",
+				"\u5b57    \u5b57x",
+			},
+		},
+		{
+			"Wrapped lines", TerminalThemeNone, true, false, 3, 10,
+			"a\n\u6f22\u5b57\u304b\u306a\u4ea4\u3058\u308a\u6587\u3067\u3059\nThe quick brown fox jumps\n\tx\nold\n",
+			"a\n\u6f22\u5b57\u30ab\u30ca\u4ea4\u3058\u308a\u6587\u3067\u3059\nThe quick red fox jumps\n\tx\nnew\nmore",
+			[]string{
+				"1 a            1 a",
+				"2 \u6f22\u5b57\u304b\u306a\u4ea4 | 2 \u6f22\u5b57\u30ab\u30ca\u4ea4",
+				"  \u3058\u308a\u6587\u3067\u3059     \u3058\u308a\u6587\u3067\u3059",
+				"3 The quick  | 3 The quick",
+				"  brown fox      red fox",
+				"  jumps          jumps",
+				"4         x    4         x",
+				"5 old        | 5 new",
+				"             > 6 more",
+				"                 \\ No",
+				"                 newline at",
+				"                 end of",
+				"                 file",
+			},
+		},
+		{
+			"Missing newline in a narrow column", TerminalThemeNone, false, false, 3, 4,
+			"a", "b",
+			[]string{
+				"a    | b",
+				"\\ No   \\ No",
+				"newl   newl",
+				"ine    ine",
+				"at     at",
+				"end    end",
+				"of     of",
+				"file   file",
+			},
+		},
+		{
+			"Colors with whitespace", TerminalTheme16, false, true, 3, 4,
+			"\tab\n", "\tac\n",
+			[]string{
+				"\x1b[31m\u2192   \x1b[0m \x1b[2m|\x1b[0m \x1b[32m\u2192   \x1b[0m",
+				"\x1b[7;31mab\x1b[0m\x1b[31m\u21b5\x1b[0m    \x1b[7;32mac\x1b[0m\x1b[32m\u21b5\x1b[0m",
+			},
+		},
+	} {
+		dmp.TerminalTheme = tc.Theme
+		dmp.TerminalLineNumbers = tc.LineNumbers
+		dmp.TerminalWhitespace = tc.Whitespace
+		dmp.UnifiedContext = tc.Context
+
+		expected := ""
+		if tc.Expected != nil {
+			expected = strings.Join(tc.Expected, "\n") + "\n"
+		}
+		actual := dmp.DiffSideBySideTerminal(dmp.DiffMain(tc.Text1, tc.Text2, false), tc.Width)
+		assert.Equal(t, expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
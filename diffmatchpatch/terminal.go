@@ -0,0 +1,226 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// TerminalTheme defines the escape codes with which diffs are colored in terminals.
+type TerminalTheme int8
+
+//go:generate stringer -type=TerminalTheme -trimprefix=TerminalTheme
+
+const (
+	// TerminalThemeNone renders diffs without any escape codes.
+	TerminalThemeNone TerminalTheme = iota
+	// TerminalTheme16 uses the 16 basic colors, which every color terminal supports.
+	TerminalTheme16
+	// TerminalTheme256 uses the 256 colors of xterm.
+	TerminalTheme256
+	// TerminalThemeTrueColor uses 24-bit colors.
+	TerminalThemeTrueColor
+)
+
+// Markers which show whitespace if TerminalWhitespace is set.
+const (
+	terminalSpaceMarker   = "·"
+	terminalTabMarker     = "→"
+	terminalReturnMarker  = "␍"
+	terminalNewlineMarker = "↵"
+)
+
+// terminalWhitespace replaces whitespace by its markers.
+var terminalWhitespace = strings.NewReplacer(" ", terminalSpaceMarker, "\t", terminalTabMarker, "\r", terminalReturnMarker)
+
+// terminalColors holds the SGR parameters of the parts of a diff, an empty string leaves a part uncolored.
+type terminalColors struct {
+	Delete     string
+	DeleteWord string
+	Insert     string
+	InsertWord string
+	Gutter     string
+	Fold       string
+}
+
+// terminalColorsFor returns the colors of a theme, either for highlighting changes with foreground or with background colors.
+func terminalColorsFor(theme TerminalTheme, background bool) terminalColors {
+	switch {
+	case theme == TerminalTheme16 && background:
+		return terminalColors{"41", "101", "42", "102", "2", "36"}
+	case theme == TerminalTheme16:
+		return terminalColors{"31", "7;31", "32", "7;32", "2", "36"}
+	case theme == TerminalTheme256 && background:
+		return terminalColors{"48;5;52", "48;5;124", "48;5;22", "48;5;28", "38;5;244", "38;5;67"}
+	case theme == TerminalTheme256:
+		return terminalColors{"38;5;203", "38;5;231;48;5;160", "38;5;77", "38;5;231;48;5;28", "38;5;244", "38;5;67"}
+	case theme == TerminalThemeTrueColor && background:
+		return terminalColors{"48;2;78;17;27", "48;2;141;35;46", "48;2;18;54;30", "48;2;30;100;48", "38;2;110;118;129", "38;2;88;166;255"}
+	case theme == TerminalThemeTrueColor:
+		return terminalColors{"38;2;248;81;73", "38;2;255;255;255;48;2;179;38;38", "38;2;63;185;80", "38;2;255;255;255;48;2;35;134;54", "38;2;110;118;129", "38;2;88;166;255"}
+	}
+	return terminalColors{}
+}
+
+// TerminalThemeFor returns the richest theme which w is able to show.
+// Writers which are not terminals get TerminalThemeNone, just like terminals for which the environment variable NO_COLOR is set or TERM is "dumb".
+// Otherwise the theme is TerminalThemeTrueColor if COLORTERM is "truecolor" or "24bit", TerminalTheme256 if TERM names a 256 color terminal, and TerminalTheme16 if not.
+func TerminalThemeFor(w io.Writer) TerminalTheme {
+	file, ok := w.(*os.File)
+	if !ok {
+		return TerminalThemeNone
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return TerminalThemeNone
+	}
+	return terminalThemeFor(info.Mode()&os.ModeCharDevice != 0, os.Getenv)
+}
+
+// terminalThemeFor returns the theme of a writer which might be a terminal, given how to look up environment variables.
+func terminalThemeFor(terminal bool, getenv func(key string) string) TerminalTheme {
+	switch {
+	case !terminal || getenv("NO_COLOR") != "" || getenv("TERM") == "dumb":
+		return TerminalThemeNone
+	case getenv("COLORTERM") == "truecolor" || getenv("COLORTERM") == "24bit":
+		return TerminalThemeTrueColor
+	case strings.Contains(getenv("TERM"), "256color"):
+		return TerminalTheme256
+	}
+	return TerminalTheme16
+}
+
+// DiffTerminal renders a line-based diff for terminals, colored by TerminalTheme.
+// Every line starts with a "-" if it was deleted, a "+" if it was inserted or a space if it is unchanged, which is preceded by a gutter with the line numbers of both texts if TerminalLineNumbers is set.
+// Deleted and inserted lines are paired up, and the words which changed within them are highlighted.
+// Unchanged lines which are more than UnifiedContext lines away from a change are folded into a "..." line.
+func (dmp *DiffMatchPatch) DiffTerminal(diffs []Diff) string {
+	return dmp.diffTerminal(diffs, dmp.TerminalTheme)
+}
+
+// DiffWriteTerminal writes a line-based diff to w like DiffTerminal, but never uses a richer theme than TerminalThemeFor(w).
+// Hence colors are left out unless w is a terminal.
+func (dmp *DiffMatchPatch) DiffWriteTerminal(w io.Writer, diffs []Diff) error {
+	theme := dmp.TerminalTheme
+	if supported := TerminalThemeFor(w); supported < theme {
+		theme = supported
+	}
+	_, err := io.WriteString(w, dmp.diffTerminal(diffs, theme))
+	return err
+}
+
+// diffTerminal renders a line-based diff for terminals with the given theme.
+func (dmp *DiffMatchPatch) diffTerminal(diffs []Diff, theme TerminalTheme) string {
+	rows := dmp.diffRows(diffs, false)
+	folded := diffFoldRows(rows, dmp.UnifiedContext)
+	colors := terminalColorsFor(theme, dmp.TerminalBackground)
+
+	// Width of the line numbers, 0 for no gutter.
+	width := 0
+	if dmp.TerminalLineNumbers {
+		for _, row := range rows {
+			width = max(width, len(strconv.Itoa(max(row.Line1, row.Line2))))
+		}
+	}
+
+	var buff bytes.Buffer
+	for i, row := range rows {
+		if folded[i] {
+			if i == 0 || !folded[i-1] {
+				_, _ = buff.WriteString(terminalPaint(colors.Fold, "...") + "\n")
+			}
+			continue
+		}
+
+		text, words := row.Text1, row.Words1
+		sign, lineColor, wordColor := " ", "", ""
+		switch row.Type {
+		case DiffDelete:
+			sign, lineColor, wordColor = "-", colors.Delete, colors.DeleteWord
+		case DiffInsert:
+			text, words = row.Text2, row.Words2
+			sign, lineColor, wordColor = "+", colors.Insert, colors.InsertWord
+		}
+
+		if width > 0 {
+			_, _ = buff.WriteString(terminalPaint(colors.Gutter, terminalGutter(row.Line1, row.Line2, width)))
+		}
+		_, _ = buff.WriteString(terminalPaint(lineColor, sign))
+		for _, aDiff := range words {
+			switch aDiff.Type {
+			case DiffEqual:
+				dmp.terminalText(&buff, aDiff.Text, lineColor, colors)
+			case row.Type:
+				dmp.terminalText(&buff, aDiff.Text, wordColor, colors)
+			}
+		}
+		if !strings.HasSuffix(text, "\n") {
+			_, _ = buff.WriteString("\n")
+			if width > 0 {
+				_, _ = buff.WriteString(terminalPaint(colors.Gutter, terminalGutter(0, 0, width)))
+			}
+			_, _ = buff.WriteString(terminalPaint(colors.Gutter, noNewlineMarker))
+		} else if dmp.TerminalWhitespace {
+			_, _ = buff.WriteString(terminalPaint(terminalMarkerColor(lineColor, colors), terminalNewlineMarker))
+		}
+		_, _ = buff.WriteString("\n")
+	}
+
+	return buff.String()
+}
+
+// terminalText writes text in the given color, showing whitespace as markers if TerminalWhitespace is set.
+func (dmp *DiffMatchPatch) terminalText(buff *bytes.Buffer, text, color string, colors terminalColors) {
+	if !dmp.TerminalWhitespace {
+		_, _ = buff.WriteString(terminalPaint(color, text))
+		return
+	}
+	markerColor := terminalMarkerColor(color, colors)
+	if markerColor == color {
+		_, _ = buff.WriteString(terminalPaint(color, terminalWhitespace.Replace(text)))
+		return
+	}
+
+	start := 0
+	for i, r := range text {
+		if r != ' ' && r != '\t' && r != '\r' {
+			continue
+		}
+		_, _ = buff.WriteString(terminalPaint(color, text[start:i]))
+		_, _ = buff.WriteString(terminalPaint(markerColor, terminalWhitespace.Replace(string(r))))
+		start = i + 1
+	}
+	_, _ = buff.WriteString(terminalPaint(color, text[start:]))
+}
+
+// terminalMarkerColor returns the color of whitespace markers within text of the given color, which is dimmed for unchanged text.
+func terminalMarkerColor(color string, colors terminalColors) string {
+	if color == "" {
+		return colors.Gutter
+	}
+	return color
+}
+
+// terminalGutter formats the line numbers of a row, leaving out the ones which are 0.
+func terminalGutter(line1, line2, width int) string {
+	return fmt.Sprintf("%*s %*s │ ", width, diffRowNumber(line1), width, diffRowNumber(line2))
+}
+
+// terminalPaint wraps text in the escape codes of a color, unless the color or the text is empty.
+func terminalPaint(color, text string) string {
+	if color == "" || text == "" {
+		return text
+	}
+	return "\x1b[" + color + "m" + text + "\x1b[0m"
+}
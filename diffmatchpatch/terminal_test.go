@@ -0,0 +1,115 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffTerminal(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Theme       TerminalTheme
+		Background  bool
+		LineNumbers bool
+		Whitespace  bool
+		Context     int
+
+		Text1 string
+		Text2 string
+
+		Expected string
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Null case", TerminalTheme16, false, true, false, 3, "", "", ""},
+		{
+			"Folded without colors", TerminalThemeNone, false, true, false, 1,
+			"a\nb\nc\nd\n", "a\nb\nC\nd\n",
+			"...\n2 2 │  b\n3   │ -c\n  3 │ +C\n4 4 │  d\n",
+		},
+		{
+			"16 colors", TerminalTheme16, false, false, false, 3,
+			"The quick brown fox\n", "The quick red fox\n",
+			"\x1b[31m-\x1b[0m\x1b[31mThe quick \x1b[0m\x1b[7;31mbrown\x1b[0m\x1b[31m fox\x1b[0m\n" +
+				"\x1b[32m+\x1b[0m\x1b[32mThe quick \x1b[0m\x1b[7;32mred\x1b[0m\x1b[32m fox\x1b[0m\n",
+		},
+		{
+			"256 colors on the background with whitespace", TerminalTheme256, true, false, true, 3,
+			"\tx y\n", "\tx  y",
+			"\x1b[48;5;52m-\x1b[0m\x1b[48;5;52m→x\x1b[0m\x1b[48;5;124m·\x1b[0m\x1b[48;5;52my\x1b[0m\x1b[48;5;52m↵\x1b[0m\n" +
+				"\x1b[48;5;22m+\x1b[0m\x1b[48;5;22m→x\x1b[0m\x1b[48;5;28m··\x1b[0m\x1b[48;5;22my\x1b[0m\n" +
+				"\x1b[38;5;244m\\ No newline at end of file\x1b[0m\n",
+		},
+		{
+			"True colors with whitespace", TerminalThemeTrueColor, false, true, true, 3,
+			"a b\nc\n", "a b\nC\n",
+			"\x1b[38;2;110;118;129m1 1 │ \x1b[0m a\x1b[38;2;110;118;129m·\x1b[0mb\x1b[38;2;110;118;129m↵\x1b[0m\n" +
+				"\x1b[38;2;110;118;129m2   │ \x1b[0m\x1b[38;2;248;81;73m-\x1b[0m\x1b[38;2;255;255;255;48;2;179;38;38mc\x1b[0m\x1b[38;2;248;81;73m↵\x1b[0m\n" +
+				"\x1b[38;2;110;118;129m  2 │ \x1b[0m\x1b[38;2;63;185;80m+\x1b[0m\x1b[38;2;255;255;255;48;2;35;134;54mC\x1b[0m\x1b[38;2;63;185;80m↵\x1b[0m\n",
+		},
+	} {
+		dmp.TerminalTheme = tc.Theme
+		dmp.TerminalBackground = tc.Background
+		dmp.TerminalLineNumbers = tc.LineNumbers
+		dmp.TerminalWhitespace = tc.Whitespace
+		dmp.UnifiedContext = tc.Context
+
+		actual := dmp.DiffTerminal(dmp.DiffMain(tc.Text1, tc.Text2, false))
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Colors are left out unless writing to a terminal.
+	dmp = New()
+	var buff bytes.Buffer
+	assert.NoError(t, dmp.DiffWriteTerminal(&buff, dmp.DiffMain("a\n", "b\n", false)))
+	assert.Equal(t, "1   │ -a\n  1 │ +b\n", buff.String())
+}
+
+func TestTerminalThemeFor(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Terminal bool
+		Env      map[string]string
+
+		Expected TerminalTheme
+	}
+
+	for i, tc := range []TestCase{
+		{"No terminal", false, map[string]string{"TERM": "xterm-256color"}, TerminalThemeNone},
+		{"No color", true, map[string]string{"TERM": "xterm-256color", "NO_COLOR": "1"}, TerminalThemeNone},
+		{"Dumb terminal", true, map[string]string{"TERM": "dumb"}, TerminalThemeNone},
+		{"True colors", true, map[string]string{"TERM": "xterm-256color", "COLORTERM": "truecolor"}, TerminalThemeTrueColor},
+		{"24-bit colors", true, map[string]string{"COLORTERM": "24bit"}, TerminalThemeTrueColor},
+		{"256 colors", true, map[string]string{"TERM": "screen-256color"}, TerminalTheme256},
+		{"16 colors", true, map[string]string{"TERM": "xterm"}, TerminalTheme16},
+	} {
+		actual := terminalThemeFor(tc.Terminal, func(key string) string {
+			return tc.Env[key]
+		})
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	assert.Equal(t, TerminalThemeNone, TerminalThemeFor(&bytes.Buffer{}))
+
+	file, err := os.Create(filepath.Join(t.TempDir(), "diff"))
+	assert.NoError(t, err)
+	defer file.Close()
+	assert.Equal(t, TerminalThemeNone, TerminalThemeFor(file))
+}
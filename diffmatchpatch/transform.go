@@ -0,0 +1,79 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DiffTransform transforms two concurrent diffs of the same text against each other, as known from operational transformation.
+// diffs1 turns the text into text1 and diffs2 turns it into text2. Returns a diff which applies the changes of diffs1 to text2 and a diff which applies the changes of diffs2 to text1, both of which result in the same text.
+// Text deleted by both diffs is deleted once. Insertions at the same position are ordered by their text, so that the resulting text does not depend on the order of the arguments. Returns an error if the diffs do not agree on the text they change.
+func (dmp *DiffMatchPatch) DiffTransform(diffs1, diffs2 []Diff) ([]Diff, []Diff, error) {
+	sparse1 := diffsToSparse(diffs1, UnitByte)
+	sparse2 := diffsToSparse(diffs2, UnitByte)
+	var transformed1, transformed2 []sparseDiff
+
+	// The rest of the current operation of either diff.
+	var diff1, diff2 sparseDiff
+	for {
+		for diff1.Len == 0 && len(sparse1) != 0 {
+			diff1, sparse1 = sparse1[0], sparse1[1:]
+		}
+		for diff2.Len == 0 && len(sparse2) != 0 {
+			diff2, sparse2 = sparse2[0], sparse2[1:]
+		}
+		if diff1.Len == 0 && diff2.Len == 0 {
+			break
+		}
+
+		// Insertions do not touch the common text, the other diff keeps them as equalities.
+		insert1 := diff1.Len != 0 && diff1.Type == DiffInsert
+		insert2 := diff2.Len != 0 && diff2.Type == DiffInsert
+		if insert1 && (!insert2 || diff1.Text <= diff2.Text) {
+			transformed1 = sparseAppend(transformed1, diff1)
+			transformed2 = sparseAppend(transformed2, sparseDiff{DiffEqual, diff1.Text, diff1.Len})
+			diff1 = sparseDiff{}
+			continue
+		}
+		if insert2 {
+			transformed1 = sparseAppend(transformed1, sparseDiff{DiffEqual, diff2.Text, diff2.Len})
+			transformed2 = sparseAppend(transformed2, diff2)
+			diff2 = sparseDiff{}
+			continue
+		}
+
+		// Everything else covers the common text, so go over it in pieces which both diffs agree on.
+		if diff1.Len == 0 || diff2.Len == 0 {
+			return nil, nil, errors.New("Transformed diffs disagree on the length of the text")
+		}
+		n := min(diff1.Len, diff2.Len)
+		var piece1, piece2 sparseDiff
+		piece1, diff1 = diff1.split(n, UnitByte)
+		piece2, diff2 = diff2.split(n, UnitByte)
+		if piece1.Text != piece2.Text {
+			return nil, nil, fmt.Errorf("Transformed diffs disagree on the text: %q != %q", piece1.Text, piece2.Text)
+		}
+
+		switch {
+		case piece1.Type == DiffDelete && piece2.Type == DiffDelete:
+			// Text deleted by both diffs is already gone.
+		case piece1.Type == DiffDelete:
+			transformed1 = sparseAppend(transformed1, piece1)
+		case piece2.Type == DiffDelete:
+			transformed2 = sparseAppend(transformed2, piece2)
+		default:
+			transformed1 = sparseAppend(transformed1, piece1)
+			transformed2 = sparseAppend(transformed2, piece2)
+		}
+	}
+
+	return sparseToDiffs(transformed1), sparseToDiffs(transformed2), nil
+}
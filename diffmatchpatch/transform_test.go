@@ -0,0 +1,90 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffTransform(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Diffs1 []Diff
+		Diffs2 []Diff
+
+		Expected1     []Diff
+		Expected2     []Diff
+		ErrorExpected bool
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Null case", []Diff{}, []Diff{}, []Diff{}, []Diff{}, false},
+		{"Apart", []Diff{{DiffInsert, "x"}, {DiffEqual, "abc"}}, []Diff{{DiffEqual, "abc"}, {DiffInsert, "y"}}, []Diff{{DiffInsert, "x"}, {DiffEqual, "abcy"}}, []Diff{{DiffEqual, "xabc"}, {DiffInsert, "y"}}, false},
+		{"Insertions at the same position", []Diff{{DiffEqual, "a"}, {DiffInsert, "2"}, {DiffEqual, "b"}}, []Diff{{DiffEqual, "a"}, {DiffInsert, "1"}, {DiffEqual, "b"}}, []Diff{{DiffEqual, "a1"}, {DiffInsert, "2"}, {DiffEqual, "b"}}, []Diff{{DiffEqual, "a"}, {DiffInsert, "1"}, {DiffEqual, "2b"}}, false},
+		{"Overlapping deletions", []Diff{{DiffEqual, "a"}, {DiffDelete, "bc"}, {DiffEqual, "d"}}, []Diff{{DiffEqual, "ab"}, {DiffDelete, "cd"}}, []Diff{{DiffEqual, "a"}, {DiffDelete, "b"}}, []Diff{{DiffEqual, "a"}, {DiffDelete, "d"}}, false},
+		{"Insertion into a deletion", []Diff{{DiffEqual, "a"}, {DiffInsert, "ö"}, {DiffEqual, "bc"}}, []Diff{{DiffDelete, "abc"}, {DiffInsert, "ü"}}, []Diff{{DiffInsert, "ö"}, {DiffEqual, "ü"}}, []Diff{{DiffDelete, "a"}, {DiffEqual, "ö"}, {DiffDelete, "bc"}, {DiffInsert, "ü"}}, false},
+		{"Texts differ", []Diff{{DiffEqual, "abc"}}, []Diff{{DiffEqual, "abd"}}, nil, nil, true},
+		{"Lengths differ", []Diff{{DiffEqual, "abc"}}, []Diff{{DiffEqual, "ab"}}, nil, nil, true},
+	} {
+		actual1, actual2, err := dmp.DiffTransform(tc.Diffs1, tc.Diffs2)
+		if tc.ErrorExpected {
+			assert.Error(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+			continue
+		}
+		assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Expected1, actual1, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Expected2, actual2, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Concurrent random edits of runes from the corpus converge.
+	text1, text2 := multilingualTexts()
+	corpus := []rune(text1 + text2)
+	random := rand.New(rand.NewSource(1))
+	edit := func(runes []rune) string {
+		runes = append([]rune{}, runes...)
+		for j := 0; j < 5 && len(runes) > 0; j++ {
+			k := random.Intn(len(runes))
+			replacement := corpus[random.Intn(len(corpus)-10):][:random.Intn(10)]
+			runes = append(append(append([]rune{}, runes[:k]...), replacement...), runes[k+random.Intn(min(3, len(runes)-k)):]...)
+		}
+		return string(runes)
+	}
+	for i := 0; i < 200; i++ {
+		runes := corpus[random.Intn(len(corpus)/2):]
+		runes = runes[:random.Intn(min(len(runes), 200))]
+		base := string(runes)
+		text1, text2 := edit(runes), edit(runes)
+		diffs1 := dmp.DiffMain(base, text1, false)
+		diffs2 := dmp.DiffMain(base, text2, false)
+
+		actual1, actual2, err := dmp.DiffTransform(diffs1, diffs2)
+		assert.NoError(t, err, fmt.Sprintf("Random case #%d", i))
+		assert.Equal(t, text2, dmp.DiffText1(actual1), fmt.Sprintf("Random case #%d", i))
+		assert.Equal(t, text1, dmp.DiffText1(actual2), fmt.Sprintf("Random case #%d", i))
+		assert.Equal(t, dmp.DiffText2(actual1), dmp.DiffText2(actual2), fmt.Sprintf("Random case #%d", i))
+
+		// The order of the arguments does not matter.
+		swapped2, swapped1, err := dmp.DiffTransform(diffs2, diffs1)
+		assert.NoError(t, err, fmt.Sprintf("Random case #%d", i))
+		assert.Equal(t, dmp.DiffText2(actual1), dmp.DiffText2(swapped1), fmt.Sprintf("Random case #%d", i))
+		assert.Equal(t, dmp.DiffText2(actual2), dmp.DiffText2(swapped2), fmt.Sprintf("Random case #%d", i))
+
+		// A diff transformed against itself converges as well, deleting its text just once.
+		actual1, actual2, err = dmp.DiffTransform(diffs1, diffs1)
+		assert.NoError(t, err, fmt.Sprintf("Random case #%d", i))
+		assert.Equal(t, dmp.DiffText2(actual1), dmp.DiffText2(actual2), fmt.Sprintf("Random case #%d", i))
+	}
+}
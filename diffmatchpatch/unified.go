@@ -0,0 +1,463 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// noNewlineMarker is the line GNU diff emits after a line which has no trailing newline.
+const noNewlineMarker = "\\ No newline at end of file"
+
+// unifiedHunkHeader matches a hunk header with an optional section heading, e.g. "@@ -1,3 +1,4 @@ func main() {".
+var unifiedHunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@(?: (.*))?$`)
+
+// UnifiedHunk represents one "@@" hunk of a unified diff.
+// Every Diff in Lines holds exactly one line including its trailing newline, which is only missing on the last line of a text.
+type UnifiedHunk struct {
+	Lines   []Diff
+	Start1  int
+	Start2  int
+	Length1 int
+	Length2 int
+	// Section is the optional heading after the hunk header, e.g. the enclosing function as printed by "git diff".
+	Section string
+}
+
+// UnifiedFile represents the unified diff of one file.
+type UnifiedFile struct {
+	// Header holds extended header lines, e.g. "diff --git a/file b/file" and "index 83db48f..bf269f4 100644".
+	Header  []string
+	OldName string
+	NewName string
+	Hunks   []UnifiedHunk
+}
+
+// String emulates GNU diff's unified format.
+// Header: @@ -382,8 +481,9 @@
+// Indices are line numbers which are printed as 1-based, not 0-based.
+func (h *UnifiedHunk) String() string {
+	var text bytes.Buffer
+	_, _ = text.WriteString("@@ -" + unifiedRange(h.Start1, h.Length1) + " +" + unifiedRange(h.Start2, h.Length2) + " @@")
+	if len(h.Section) != 0 {
+		_, _ = text.WriteString(" " + h.Section)
+	}
+	_, _ = text.WriteString("\n")
+
+	for _, aDiff := range h.Lines {
+		switch aDiff.Type {
+		case DiffInsert:
+			_, _ = text.WriteString("+")
+		case DiffDelete:
+			_, _ = text.WriteString("-")
+		case DiffEqual:
+			_, _ = text.WriteString(" ")
+		}
+
+		_, _ = text.WriteString(aDiff.Text)
+		if !strings.HasSuffix(aDiff.Text, "\n") {
+			_, _ = text.WriteString("\n" + noNewlineMarker + "\n")
+		}
+	}
+
+	return text.String()
+}
+
+// String emulates the output of "diff -u", i.e. the extended header, the "---" and "+++" file headers and all hunks.
+// The file headers are omitted if there are no hunks.
+func (f *UnifiedFile) String() string {
+	var text bytes.Buffer
+	for _, line := range f.Header {
+		_, _ = text.WriteString(line + "\n")
+	}
+	if len(f.Hunks) == 0 {
+		return text.String()
+	}
+
+	_, _ = text.WriteString("--- " + f.OldName + "\n")
+	_, _ = text.WriteString("+++ " + f.NewName + "\n")
+	for i := range f.Hunks {
+		_, _ = text.WriteString(f.Hunks[i].String())
+	}
+
+	return text.String()
+}
+
+// unifiedRange formats the line range of one side of a hunk header the way GNU diff does.
+func unifiedRange(start, length int) string {
+	if length == 0 {
+		// An empty range refers to the line before it.
+		return strconv.Itoa(start) + ",0"
+	} else if length == 1 {
+		return strconv.Itoa(start + 1)
+	}
+	return strconv.Itoa(start+1) + "," + strconv.Itoa(length)
+}
+
+// DiffUnified computes a line-based diff of the two texts and returns it in GNU unified format.
+// The lines are compared the way GNU diff compares them with as many lines of context, so that the output is the same as that of "diff -U<UnifiedContext>".
+// fromFile and toFile are written verbatim into the "---" and "+++" headers.
+func (dmp *DiffMatchPatch) DiffUnified(text1, text2, fromFile, toFile string) string {
+	f := UnifiedFile{
+		OldName: fromFile,
+		NewName: toFile,
+		Hunks:   dmp.unifiedHunks(diffLinesGNU(text1, text2, max(0, dmp.UnifiedContext))),
+	}
+	return f.String()
+}
+
+// DiffToUnified converts a []Diff into a unified diff, e.g. the result of DiffMainRunes on the output of DiffLinesToRunes after it was rehydrated with DiffCharsToLines.
+// Diffs which do not end on line boundaries are recomputed line by line first.
+func (dmp *DiffMatchPatch) DiffToUnified(diffs []Diff, fromFile, toFile string) string {
+	f := UnifiedFile{
+		OldName: fromFile,
+		NewName: toFile,
+		Hunks:   dmp.DiffToUnifiedHunks(diffs),
+	}
+	return f.String()
+}
+
+// DiffToUnifiedHunks splits a []Diff into the hunks of a unified diff with UnifiedContext lines of context.
+// Changes which are at most twice the context apart share one hunk.
+func (dmp *DiffMatchPatch) DiffToUnifiedHunks(diffs []Diff) []UnifiedHunk {
+	return dmp.unifiedHunks(dmp.diffToLines(diffs))
+}
+
+// unifiedHunks splits a diff with one Diff per line into the hunks of a unified diff with UnifiedContext lines of context.
+func (dmp *DiffMatchPatch) unifiedHunks(lines []Diff) []UnifiedHunk {
+	context := max(0, dmp.UnifiedContext)
+
+	// Line numbers in both texts at which each line starts.
+	line1 := make([]int, len(lines)+1)
+	line2 := make([]int, len(lines)+1)
+	for i, aDiff := range lines {
+		line1[i+1] = line1[i]
+		line2[i+1] = line2[i]
+		if aDiff.Type != DiffInsert {
+			line1[i+1]++
+		}
+		if aDiff.Type != DiffDelete {
+			line2[i+1]++
+		}
+	}
+
+	hunks := []UnifiedHunk{}
+	pointer := 0
+	for pointer < len(lines) {
+		if lines[pointer].Type == DiffEqual {
+			pointer++
+			continue
+		}
+
+		start := max(0, pointer-context)
+		end := pointer
+		for {
+			// Skip the changes and look at the equality following them.
+			for end < len(lines) && lines[end].Type != DiffEqual {
+				end++
+			}
+			next := end
+			for next < len(lines) && lines[next].Type == DiffEqual {
+				next++
+			}
+			if next == len(lines) || next-end > 2*context {
+				break
+			}
+			// The next change is close enough to be merged into this hunk.
+			end = next
+		}
+		end = min(len(lines), end+context)
+
+		hunks = append(hunks, UnifiedHunk{
+			Lines:   lines[start:end],
+			Start1:  line1[start],
+			Start2:  line2[start],
+			Length1: line1[end] - line1[start],
+			Length2: line2[end] - line2[start],
+		})
+		pointer = end
+	}
+
+	return hunks
+}
+
+// diffToLines splits a []Diff into one Diff per line the way a unified diff shows them.
+// Diffs which do not end on line boundaries are recomputed line by line first.
+func (dmp *DiffMatchPatch) diffToLines(diffs []Diff) []Diff {
+	if !diffLinesAligned(diffs) {
+		return diffLinesGNU(dmp.DiffText1(diffs), dmp.DiffText2(diffs), max(0, dmp.UnifiedContext))
+	}
+	return diffShiftLines(diffSplitLines(diffs))
+}
+
+// diffLinesExact computes a diff of the two texts line by line without refining it character by character.
+func (dmp *DiffMatchPatch) diffLinesExact(text1, text2 string) []Diff {
+	chars1, chars2, lineArray := dmp.DiffLinesToRunes(text1, text2)
+	diffs := dmp.DiffMainRunes(chars1, chars2, false)
+	return dmp.DiffCharsToLines(diffs, lineArray)
+}
+
+// diffLinesAligned reports whether every diff starts at the beginning of a line in both texts.
+func diffLinesAligned(diffs []Diff) bool {
+	// Index of the last diff contributing to each text.
+	last1, last2 := -1, -1
+	for i, aDiff := range diffs {
+		if len(aDiff.Text) == 0 {
+			continue
+		}
+		if aDiff.Type != DiffInsert {
+			last1 = i
+		}
+		if aDiff.Type != DiffDelete {
+			last2 = i
+		}
+	}
+
+	for i, aDiff := range diffs {
+		if len(aDiff.Text) == 0 || strings.HasSuffix(aDiff.Text, "\n") {
+			continue
+		}
+		// Only the last line of a text may lack its newline.
+		if (aDiff.Type != DiffInsert && i != last1) || (aDiff.Type != DiffDelete && i != last2) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// diffSplitLines splits line aligned diffs into one Diff per line.
+// Within each block of changes all deletions are moved before all insertions like GNU diff prints them.
+func diffSplitLines(diffs []Diff) []Diff {
+	var lines, deletions, insertions []Diff
+	for _, aDiff := range diffs {
+		for _, line := range strings.SplitAfter(aDiff.Text, "\n") {
+			if len(line) == 0 {
+				continue
+			}
+			switch aDiff.Type {
+			case DiffInsert:
+				insertions = append(insertions, Diff{DiffInsert, line})
+			case DiffDelete:
+				deletions = append(deletions, Diff{DiffDelete, line})
+			case DiffEqual:
+				lines = append(lines, deletions...)
+				lines = append(lines, insertions...)
+				deletions = deletions[:0]
+				insertions = insertions[:0]
+				lines = append(lines, Diff{DiffEqual, line})
+			}
+		}
+	}
+	lines = append(lines, deletions...)
+	lines = append(lines, insertions...)
+
+	return lines
+}
+
+// UnifiedToText takes a list of unified file diffs and returns their textual representation.
+func (dmp *DiffMatchPatch) UnifiedToText(files []UnifiedFile) string {
+	var text bytes.Buffer
+	for i := range files {
+		_, _ = text.WriteString(files[i].String())
+	}
+	return text.String()
+}
+
+// UnifiedFromText parses a unified diff as produced by "diff -u" or "git diff" which may span multiple files.
+// Lines outside of file headers and hunks, e.g. a commit message, are ignored. Hunks without file headers are collected in a file without names.
+func (dmp *DiffMatchPatch) UnifiedFromText(text string) ([]UnifiedFile, error) {
+	files := []UnifiedFile{}
+	if len(text) == 0 {
+		return files, nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+
+	// inHeader is true while the lines following a "diff" line are collected as extended header.
+	inHeader := false
+	for pointer := 0; pointer < len(lines); pointer++ {
+		line := strings.TrimSuffix(lines[pointer], "\n")
+
+		switch {
+		case strings.HasPrefix(line, "diff "):
+			files = append(files, UnifiedFile{Header: []string{line}})
+			inHeader = true
+		case strings.HasPrefix(line, "--- ") && pointer+1 < len(lines) && strings.HasPrefix(lines[pointer+1], "+++ "):
+			if !inHeader {
+				files = append(files, UnifiedFile{})
+			}
+			inHeader = false
+			pointer++
+			files[len(files)-1].OldName = unifiedFileName(line[4:])
+			files[len(files)-1].NewName = unifiedFileName(strings.TrimSuffix(lines[pointer], "\n")[4:])
+		case strings.HasPrefix(line, "@@ "):
+			if len(files) == 0 {
+				files = append(files, UnifiedFile{})
+			}
+			inHeader = false
+			hunk, n, err := unifiedHunkFromLines(lines[pointer:])
+			if err != nil {
+				return files, err
+			}
+			files[len(files)-1].Hunks = append(files[len(files)-1].Hunks, hunk)
+			pointer += n - 1
+		default:
+			if inHeader {
+				files[len(files)-1].Header = append(files[len(files)-1].Header, line)
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// unifiedFileName strips the timestamp which GNU diff appends to file names after a tab.
+func unifiedFileName(name string) string {
+	if i := strings.IndexByte(name, '\t'); i != -1 {
+		name = name[:i]
+	}
+	return strings.TrimSuffix(name, "\r")
+}
+
+// unifiedHunkFromLines parses the hunk starting at lines[0]. Returns the hunk and the number of lines it spans.
+func unifiedHunkFromLines(lines []string) (UnifiedHunk, int, error) {
+	hunk := UnifiedHunk{}
+	header := strings.TrimSuffix(lines[0], "\n")
+	m := unifiedHunkHeader.FindStringSubmatch(header)
+	if m == nil {
+		return hunk, 0, errors.New("Invalid unified diff hunk header: " + header)
+	}
+
+	hunk.Start1, hunk.Length1 = unifiedRangeFromText(m[1], m[2])
+	hunk.Start2, hunk.Length2 = unifiedRangeFromText(m[3], m[4])
+	hunk.Section = m[5]
+
+	remaining1 := hunk.Length1
+	remaining2 := hunk.Length2
+	pointer := 1
+	for ; pointer < len(lines); pointer++ {
+		line := lines[pointer]
+		if strings.HasPrefix(line, "\\") {
+			// The previous line has no trailing newline.
+			if len(hunk.Lines) == 0 {
+				return hunk, 0, errors.New("Unexpected no newline marker in hunk: " + header)
+			}
+			last := &hunk.Lines[len(hunk.Lines)-1]
+			last.Text = strings.TrimSuffix(last.Text, "\n")
+			continue
+		}
+		if remaining1 == 0 && remaining2 == 0 {
+			break
+		}
+		if !strings.HasSuffix(line, "\n") {
+			line += "\n"
+		}
+
+		var op Operation
+		switch line[0] {
+		case ' ':
+			op = DiffEqual
+		case '\n':
+			// Some editors strip the single space of blank context lines.
+			op = DiffEqual
+			line = " " + line
+		case '-':
+			op = DiffDelete
+		case '+':
+			op = DiffInsert
+		default:
+			return hunk, 0, errors.New("Invalid unified diff line '" + strings.TrimSuffix(line, "\n") + "' in hunk: " + header)
+		}
+
+		if op != DiffInsert {
+			remaining1--
+		}
+		if op != DiffDelete {
+			remaining2--
+		}
+		if remaining1 < 0 || remaining2 < 0 {
+			return hunk, 0, errors.New("Unified diff hunk is longer than its header: " + header)
+		}
+		hunk.Lines = append(hunk.Lines, Diff{op, line[1:]})
+	}
+
+	if remaining1 != 0 || remaining2 != 0 {
+		return hunk, 0, errors.New("Unified diff hunk is shorter than its header: " + header)
+	}
+
+	return hunk, pointer, nil
+}
+
+// unifiedRangeFromText converts the start and optional length of one side of a hunk header into a 0-based start and a length.
+func unifiedRangeFromText(start, length string) (int, int) {
+	s, _ := strconv.Atoi(start)
+	if len(length) == 0 {
+		return s - 1, 1
+	}
+	l, _ := strconv.Atoi(length)
+	if l == 0 {
+		// An empty range refers to the line before it.
+		return s, 0
+	}
+	return s - 1, l
+}
+
+// UnifiedApply merges the hunks of a unified diff onto the text. Returns the patched text, as well as an array of true/false values indicating which hunks were applied.
+// Hunks which drifted from their stated line numbers are located using the fuzzy matching of PatchApply, governed by MatchThreshold, MatchDistance and PatchDeleteThreshold. Every hunk is either applied as a whole or not at all.
+func (dmp *DiffMatchPatch) UnifiedApply(hunks []UnifiedHunk, text string) (string, []bool) {
+	text, results, _ := dmp.patchApply(context.Background(), dmp.unifiedToPatches(hunks, text), text, false, false)
+	return text, patchResultsApplied(results)
+}
+
+// unifiedToPatches converts line based hunks into character based patches which are expected at the respective lines of text.
+func (dmp *DiffMatchPatch) unifiedToPatches(hunks []UnifiedHunk, text string) []Patch {
+	// Character offset at which each line of text starts.
+	lineStarts := []int{0}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+
+	patches := make([]Patch, 0, len(hunks))
+	// delta keeps track of the size difference the previous hunks cause, as Start2 is expected after their application.
+	delta := 0
+	for _, hunk := range hunks {
+		patch := Patch{}
+		for _, line := range hunk.Lines {
+			if n := len(patch.diffs); n != 0 && patch.diffs[n-1].Type == line.Type {
+				patch.diffs[n-1].Text += line.Text
+			} else {
+				patch.diffs = append(patch.diffs, line)
+			}
+		}
+		patch.Length1 = len(dmp.DiffText1(patch.diffs))
+		patch.Length2 = len(dmp.DiffText2(patch.diffs))
+
+		if hunk.Start1 < len(lineStarts) {
+			patch.Start1 = lineStarts[max(0, hunk.Start1)]
+		} else {
+			patch.Start1 = len(text)
+		}
+		patch.Start2 = patch.Start1 + delta
+		delta += patch.Length2 - patch.Length1
+
+		patches = append(patches, patch)
+	}
+
+	return patches
+}
@@ -0,0 +1,294 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedHunkString(t *testing.T) {
+	type TestCase struct {
+		Hunk UnifiedHunk
+
+		Expected string
+	}
+
+	for i, tc := range []TestCase{
+		{
+			Hunk: UnifiedHunk{
+				Start1:  0,
+				Start2:  0,
+				Length1: 2,
+				Length2: 1,
+
+				Lines: []Diff{
+					{DiffEqual, "a\n"},
+					{DiffDelete, "b\n"},
+				},
+			},
+
+			Expected: "@@ -1,2 +1 @@\n a\n-b\n",
+		},
+		{
+			Hunk: UnifiedHunk{
+				Start1:  0,
+				Start2:  0,
+				Length1: 0,
+				Length2: 1,
+
+				Lines: []Diff{
+					{DiffInsert, "a"},
+				},
+			},
+
+			Expected: "@@ -0,0 +1 @@\n+a\n\\ No newline at end of file\n",
+		},
+	} {
+		actual := tc.Hunk.String()
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %#v", i, tc))
+	}
+}
+
+func TestDiffUnified(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1   string
+		Text2   string
+		Context int
+
+		Expected string
+	}
+
+	dmp := New()
+
+	letters := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\n"
+
+	// Expected outputs were produced by GNU diffutils with "diff -U<Context> --label old --label new".
+	for i, tc := range []TestCase{
+		{"Identical", letters, letters, 3, ""},
+		{"Empty to text", "", "a\nb\n", 3, "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n"},
+		{"Text to empty", "a\nb\n", "", 3, "--- old\n+++ new\n@@ -1,2 +0,0 @@\n-a\n-b\n"},
+		{"Single line", "a\n", "b\n", 3, "--- old\n+++ new\n@@ -1 +1 @@\n-a\n+b\n"},
+		{"Two hunks", letters, "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nL", 3, "--- old\n+++ new\n@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n@@ -9,4 +9,4 @@\n i\n j\n k\n-l\n+L\n\\ No newline at end of file\n"},
+		{"Merged hunks", letters, "a\nB\nc\nd\ne\nf\ng\nH\ni\nj\nk\nl\n", 3, "--- old\n+++ new\n@@ -1,11 +1,11 @@\n a\n-b\n+B\n c\n d\n e\n f\n g\n-h\n+H\n i\n j\n k\n"},
+		{"Zero context", letters, "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\n", 0, "--- old\n+++ new\n@@ -2 +2 @@\n-b\n+B\n@@ -12 +11,0 @@\n-l\n"},
+		{"Insertion without context", "a\nc\n", "a\nb\nc\n", 0, "--- old\n+++ new\n@@ -1,0 +2 @@\n+b\n"},
+		{"Missing newline", "a\nb", "a\nb\n", 3, "--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n"},
+		{"Missing newline in context", "a\nb\nc", "A\nb\nc", 3, "--- old\n+++ new\n@@ -1,3 +1,3 @@\n-a\n+A\n b\n c\n\\ No newline at end of file\n"},
+		{"Interleaved changes", "a\nb\nc\n", "x\nb\ny\n", 1, "--- old\n+++ new\n@@ -1,3 +1,3 @@\n-a\n+x\n b\n-c\n+y\n"},
+		{"Ambiguous deletion", "l2\nl2\nl1\nl0\nl2\n", "l2\nl1\nl0\nl2\n", 1, "--- old\n+++ new\n@@ -1,3 +1,2 @@\n l2\n-l2\n l1\n"},
+		{"Ambiguous deletion without context", "a\nb\nb\nc\n", "a\nb\nc\n", 0, "--- old\n+++ new\n@@ -3 +2,0 @@\n-b\n"},
+		{"Ambiguous block deletion", "x\ny\nx\ny\nz\n", "x\ny\nz\n", 1, "--- old\n+++ new\n@@ -2,4 +2,2 @@\n y\n-x\n-y\n z\n"},
+		{"Ambiguous block insertion", "x\ny\nz\n", "x\ny\nx\ny\nz\n", 1, "--- old\n+++ new\n@@ -2,2 +2,4 @@\n y\n+x\n+y\n z\n"},
+		{"Ambiguous insertion without context", "a\nb\nc\n", "a\nb\nb\nc\n", 0, "--- old\n+++ new\n@@ -2,0 +3 @@\n+b\n"},
+	} {
+		dmp.UnifiedContext = tc.Context
+
+		actual := dmp.DiffUnified(tc.Text1, tc.Text2, "old", "new")
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestDiffUnifiedGNU(t *testing.T) {
+	type TestCase struct {
+		Text1   string
+		Text2   string
+		Context int
+
+		Expected string
+	}
+
+	// Expected outputs were produced by GNU diffutils 3.8 with "diff -U<Context> --label old --label new" for random texts.
+	data, err := ioutil.ReadFile(testdataPath + "unified_gnu.json")
+	if !assert.NoError(t, err) {
+		return
+	}
+	var tests []TestCase
+	if !assert.NoError(t, json.Unmarshal(data, &tests)) {
+		return
+	}
+
+	dmp := New()
+
+	for i, tc := range tests {
+		dmp.UnifiedContext = tc.Context
+
+		actual := dmp.DiffUnified(tc.Text1, tc.Text2, "old", "new")
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d", i))
+	}
+}
+
+func TestDiffToUnified(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Diffs []Diff
+
+		Expected string
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{
+			"Line mode diffs",
+			[]Diff{{DiffEqual, "a\n"}, {DiffInsert, "x\ny\n"}, {DiffDelete, "b\n"}, {DiffEqual, "c\n"}},
+			"--- a/f\n+++ b/f\n@@ -1,3 +1,4 @@\n a\n-b\n+x\n+y\n c\n",
+		},
+		{
+			"Character diffs are recomputed",
+			[]Diff{{DiffEqual, "The c"}, {DiffDelete, "at"}, {DiffInsert, "ow"}, {DiffEqual, " sat.\nFin.\n"}},
+			"--- a/f\n+++ b/f\n@@ -1,2 +1,2 @@\n-The cat sat.\n+The cow sat.\n Fin.\n",
+		},
+	} {
+		actual := dmp.DiffToUnified(tc.Diffs, "a/f", "b/f")
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Diffs produced via the line mode helpers.
+	text1 := strings.Repeat("line\n", 10) + "old\n"
+	text2 := strings.Repeat("line\n", 10) + "new\n"
+	chars1, chars2, lineArray := dmp.DiffLinesToRunes(text1, text2)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMainRunes(chars1, chars2, false), lineArray)
+
+	actual := dmp.DiffToUnified(diffs, "a", "b")
+	assert.Equal(t, "--- a\n+++ b\n@@ -8,4 +8,4 @@\n line\n line\n line\n-old\n+new\n", actual)
+}
+
+func TestUnifiedFromText(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text string
+
+		ErrorMessagePrefix string
+	}
+
+	dmp := New()
+
+	gitDiff := "diff --git a/added.txt b/added.txt\nnew file mode 100644\nindex 0000000..3e75765\n--- /dev/null\n+++ b/added.txt\n@@ -0,0 +1 @@\n+new\ndiff --git a/main.go b/main.go\nindex 4a73987..73d83e6 100644\n--- a/main.go\n+++ b/main.go\n@@ -1,5 +1,5 @@ package main\n package main\n \n func main() {\n-\tprintln(\"hello\")\n+\tprintln(\"hello, world\")\n }\ndiff --git a/notes.txt b/notes.txt\nindex 4cb29ea..54d55bf 100644\n--- a/notes.txt\n+++ b/notes.txt\n@@ -1,3 +1,3 @@\n one\n two\n-three\n+three\n\\ No newline at end of file\n"
+
+	for i, tc := range []TestCase{
+		{"Empty", "", ""},
+		{"GNU diff", "--- old\n+++ new\n@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n@@ -9,4 +9,4 @@\n i\n j\n k\n-l\n+L\n\\ No newline at end of file\n", ""},
+		{"Git diff", gitDiff, ""},
+		{"Pure rename", "diff --git a/a b/b\nsimilarity index 100%\nrename from a\nrename to b\n", ""},
+		{"Invalid hunk header", "--- a\n+++ b\n@@ -x +1 @@\n", "Invalid unified diff hunk header: @@ -x +1 @@"},
+		{"Invalid line", "--- a\n+++ b\n@@ -1 +1 @@\n*a\n", "Invalid unified diff line '*a' in hunk: @@ -1 +1 @@"},
+		{"Hunk too short", "--- a\n+++ b\n@@ -1,2 +1,2 @@\n a\n", "Unified diff hunk is shorter than its header: @@ -1,2 +1,2 @@"},
+		{"Hunk too long", "--- a\n+++ b\n@@ -1 +1 @@\n-a\n-b\n+c\n", "Unified diff hunk is longer than its header: @@ -1 +1 @@"},
+	} {
+		files, err := dmp.UnifiedFromText(tc.Text)
+		if tc.ErrorMessagePrefix == "" {
+			assert.Nil(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+			assert.Equal(t, tc.Text, dmp.UnifiedToText(files), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		} else {
+			assert.EqualError(t, err, tc.ErrorMessagePrefix, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+	}
+
+	files, err := dmp.UnifiedFromText("From: someone\nSubject: [PATCH] Change\n---\n notes.txt | 2 +-\n\n" + gitDiff + "-- \n2.39.5\n")
+	assert.Nil(t, err)
+	assert.Len(t, files, 3)
+	assert.Equal(t, []string{"diff --git a/added.txt b/added.txt", "new file mode 100644", "index 0000000..3e75765"}, files[0].Header)
+	assert.Equal(t, "/dev/null", files[0].OldName)
+	assert.Equal(t, "b/added.txt", files[0].NewName)
+	assert.Equal(t, []UnifiedHunk{{Lines: []Diff{{DiffInsert, "new\n"}}, Start1: 0, Start2: 0, Length1: 0, Length2: 1}}, files[0].Hunks)
+	assert.Equal(t, "package main", files[1].Hunks[0].Section)
+	assert.Equal(t, []Diff{{DiffEqual, "one\n"}, {DiffEqual, "two\n"}, {DiffDelete, "three\n"}, {DiffInsert, "three"}}, files[2].Hunks[0].Lines)
+
+	files, err = dmp.UnifiedFromText("--- a.txt\t2002-02-21 23:30:39.942229878 -0800\n+++ b.txt\t2002-02-21 23:30:50.442260588 -0800\n@@ -1,3 +1,3 @@\n a\n\n-b\n+c\n")
+	assert.Nil(t, err)
+	assert.Equal(t, "a.txt", files[0].OldName)
+	assert.Equal(t, "b.txt", files[0].NewName)
+	assert.Equal(t, []Diff{{DiffEqual, "a\n"}, {DiffEqual, "\n"}, {DiffDelete, "b\n"}, {DiffInsert, "c\n"}}, files[0].Hunks[0].Lines[:4])
+}
+
+func TestUnifiedApply(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1    string
+		Text2    string
+		TextBase string
+
+		Expected        string
+		ExpectedApplies []bool
+	}
+
+	dmp := New()
+	dmp.UnifiedContext = 1
+
+	lines := func(s ...string) string {
+		return strings.Join(s, "\n") + "\n"
+	}
+	text1 := lines("alpha", "beta", "gamma", "delta", "epsilon", "zeta", "eta", "theta", "iota", "kappa")
+	text2 := lines("alpha", "BETA", "gamma", "delta", "epsilon", "zeta", "eta", "theta", "IOTA", "kappa")
+
+	for i, tc := range []TestCase{
+		{"Null case", "", "", "Hello world.", "Hello world.", []bool{}},
+		{"Exact match", text1, text2, text1, text2, []bool{true, true}},
+		{"Drifted hunks", text1, text2, "header\n" + text1, "header\n" + text2, []bool{true, true}},
+		{"Fuzzy match", text1, text2, strings.Replace(text1, "alpha", "alpha!", 1), strings.Replace(text2, "alpha", "alpha!", 1), []bool{true, true}},
+		{"Failed match", text1, text2, lines("alpha", "beta", "gamma", "nothing", "else", "matches"), lines("alpha", "BETA", "gamma", "nothing", "else", "matches"), []bool{true, false}},
+		{"Add newline at end", "a\nb", "a\nb\n", "a\nb", "a\nb\n", []bool{true}},
+		{"New file", "", "new\n", "", "new\n", []bool{true}},
+	} {
+		files, err := dmp.UnifiedFromText(dmp.DiffUnified(tc.Text1, tc.Text2, "a", "b"))
+		assert.Nil(t, err)
+
+		var hunks []UnifiedHunk
+		if len(files) != 0 {
+			hunks = files[0].Hunks
+		}
+
+		actual, actualApplies := dmp.UnifiedApply(hunks, tc.TextBase)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedApplies, actualApplies, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+}
+
+func TestUnifiedApplyWithoutContext(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Hunks    string
+		TextBase string
+
+		Expected        string
+		ExpectedApplies []bool
+	}
+
+	dmp := New()
+
+	// Hunks as written by diff -U0 and hunks with short context are located at their line numbers.
+	for i, tc := range []TestCase{
+		{"Deletion", "@@ -2 +1,0 @@\n-b\n", "b\nb\n", "b\n", []bool{true}},
+		{"Deletion at start of file", "@@ -1 +0,0 @@\n-a\n", "a\nb\n", "b\n", []bool{true}},
+		{"Insertion at start of file", "@@ -0,0 +1 @@\n+a\n", "b\nc\n", "a\nb\nc\n", []bool{true}},
+		{"Insertion at end of file", "@@ -2,0 +3 @@\n+c\n", "a\nb\n", "a\nb\nc\n", []bool{true}},
+		{"Change at end of file", "@@ -2 +2 @@\n-b\n+B\n", "b\nb\n", "b\nB\n", []bool{true}},
+		{"Short context", "@@ -3,2 +3,2 @@\n a\n-b\n+c\n", "a\nb\na\nb\n", "a\nb\na\nc\n", []bool{true}},
+	} {
+		files, err := dmp.UnifiedFromText("--- a\n+++ b\n" + tc.Hunks)
+		assert.Nil(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		actual, actualApplies := dmp.UnifiedApply(files[0].Hunks, tc.TextBase)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedApplies, actualApplies, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
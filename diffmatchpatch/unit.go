@@ -0,0 +1,144 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"unicode/utf8"
+)
+
+// Unit defines the unit in which patches count positions and lengths.
+type Unit int8
+
+//go:generate stringer -type=Unit -trimprefix=Unit
+
+const (
+	// UnitByte counts the bytes of the UTF-8 encoding, just like indexing a Go string.
+	UnitByte Unit = iota
+	// UnitRune counts Unicode code points, which does not depend on the encoding of the text and matches how DiffToDelta counts.
+	UnitRune
+	// UnitGrapheme counts extended grapheme clusters as split up by TokenizeGraphemes, i.e. what readers perceive as characters. Only fuzzy matching supports it.
+	UnitGrapheme
+)
+
+// unitLen returns the length of text in the given unit.
+func unitLen(text string, unit Unit) int {
+	switch unit {
+	case UnitRune:
+		return utf8.RuneCountInString(text)
+	case UnitGrapheme:
+		return len(TokenizeGraphemes(text))
+	}
+	return len(text)
+}
+
+// unitIndex returns the byte index of the position which is n units into text.
+// Positions beyond the end of text are extrapolated as if text was followed by single byte runes.
+func unitIndex(text string, n int, unit Unit) int {
+	if unit == UnitByte || n <= 0 {
+		return n
+	}
+	i := 0
+	if unit == UnitGrapheme {
+		for _, token := range TokenizeGraphemes(text) {
+			if n == 0 {
+				break
+			}
+			i += len(token)
+			n--
+		}
+		return i + n
+	}
+	for ; n > 0 && i < len(text); n-- {
+		_, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+	}
+	return i + n
+}
+
+// runeBoundaryBefore moves the byte index i back to the start of the rune it points into.
+// Invalid UTF-8 is treated byte by byte, so at most utf8.UTFMax-1 bytes are skipped.
+func runeBoundaryBefore(text string, i int) int {
+	if i <= 0 || i >= len(text) {
+		return i
+	}
+	for j := i; j >= 0 && j > i-utf8.UTFMax; j-- {
+		if utf8.RuneStart(text[j]) {
+			r, size := utf8.DecodeRuneInString(text[j:])
+			if j+size > i && !(r == utf8.RuneError && size == 1) {
+				return j
+			}
+			break
+		}
+	}
+	return i
+}
+
+// runeBoundaryAfter moves the byte index i forward to the end of the rune it points into.
+func runeBoundaryAfter(text string, i int) int {
+	j := runeBoundaryBefore(text, i)
+	if j == i {
+		return i
+	}
+	_, size := utf8.DecodeRuneInString(text[j:])
+	return j + size
+}
+
+// unitPrefix returns the longest prefix of text which is at most n units long without splitting a rune.
+// If n is positive the prefix holds at least one rune or grapheme cluster, so that callers consuming text in chunks always make progress.
+func unitPrefix(text string, n int, unit Unit) string {
+	if n <= 0 {
+		return ""
+	}
+	i := runeBoundaryBefore(text, min(len(text), unitIndex(text, n, unit)))
+	if i == 0 {
+		_, i = utf8.DecodeRuneInString(text)
+	}
+	return text[:i]
+}
+
+// unitSuffix returns the longest suffix of text which is at most n units long without splitting a rune.
+func unitSuffix(text string, n int, unit Unit) string {
+	i := max(0, len(text)-n)
+	if unit != UnitByte {
+		i = unitIndex(text, max(0, unitLen(text, unit)-n), unit)
+	}
+	return text[runeBoundaryAfter(text, i):]
+}
+
+// patchesToUnit converts the positions and lengths of patches from one unit to another.
+// The patches have a rolling context and are placed relative to text, the text they are made from or will be applied to.
+func (dmp *DiffMatchPatch) patchesToUnit(patches []Patch, text string, from, to Unit) []Patch {
+	if from == to {
+		return patches
+	}
+	// The text the next patch is applied to, which the context of the patch may take from the changes of the preceding ones.
+	patched := text
+	for i := range patches {
+		aPatch := &patches[i]
+		// The location of the patch in patched, in bytes.
+		start := unitIndex(patched, aPatch.Start2, from)
+		end := unitIndex(patched, aPatch.Start2+aPatch.Length1, from)
+
+		start2 := start
+		if start > len(patched) {
+			start2 = unitLen(patched, to) + start - len(patched)
+		} else if start > 0 {
+			start2 = unitLen(patched[:runeBoundaryBefore(patched, start)], to)
+		}
+		aPatch.Start1 = start2 + aPatch.Start1 - aPatch.Start2
+		aPatch.Start2 = start2
+		aPatch.Length1 = unitLen(dmp.DiffText1(aPatch.diffs), to)
+		aPatch.Length2 = unitLen(dmp.DiffText2(aPatch.diffs), to)
+
+		start = runeBoundaryBefore(patched, min(max(0, start), len(patched)))
+		end = max(start, min(end, len(patched)))
+		patched = patched[:start] + dmp.DiffText2(aPatch.diffs) + patched[end:]
+	}
+	return patches
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuneBoundary(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text  string
+		Index int
+
+		ExpectedBefore int
+		ExpectedAfter  int
+	}
+
+	for i, tc := range []TestCase{
+		{"Empty", "", 0, 0, 0},
+		{"ASCII", "abc", 1, 1, 1},
+		{"Rune start", "aéb", 1, 1, 1},
+		{"Within two bytes", "aéb", 2, 1, 3},
+		{"Within four bytes", "\U0001f98a!", 3, 0, 4},
+		{"End of text", "é", 2, 2, 2},
+		{"Invalid UTF-8", "a\x80\x80\x80\x80b", 3, 3, 3},
+		{"Truncated sequence", "a\xe6\x97b", 2, 2, 2},
+	} {
+		assert.Equal(t, tc.ExpectedBefore, runeBoundaryBefore(tc.Text, tc.Index), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedAfter, runeBoundaryAfter(tc.Text, tc.Index), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestUnitPrefixAndSuffix(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text string
+		N    int
+		Unit Unit
+
+		ExpectedLen    int
+		ExpectedPrefix string
+		ExpectedSuffix string
+	}
+
+	for i, tc := range []TestCase{
+		{"Empty", "", 3, UnitByte, 0, "", ""},
+		{"ASCII bytes", "abcdef", 4, UnitByte, 6, "abcd", "cdef"},
+		{"ASCII runes", "abcdef", 4, UnitRune, 6, "abcd", "cdef"},
+		{"CJK bytes", "日本語", 4, UnitByte, 9, "日", "語"},
+		{"CJK runes", "日本語", 2, UnitRune, 3, "日本", "本語"},
+		{"At least one rune", "日本語", 2, UnitByte, 9, "日", ""},
+		{"Longer than text", "🦊🐶", 10, UnitRune, 2, "🦊🐶", "🦊🐶"},
+		{"Nothing", "🦊🐶", 0, UnitRune, 2, "", ""},
+		{"Combining marks in graphemes", "cafe\u0301s", 4, UnitGrapheme, 5, "cafe\u0301", "afe\u0301s"},
+		{"Emoji sequence in graphemes", "\U0001f469\u200d\U0001f4bb \U0001f98a", 1, UnitGrapheme, 3, "\U0001f469\u200d\U0001f4bb", "\U0001f98a"},
+	} {
+		assert.Equal(t, tc.ExpectedLen, unitLen(tc.Text, tc.Unit), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedPrefix, unitPrefix(tc.Text, tc.N, tc.Unit), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedSuffix, unitSuffix(tc.Text, tc.N, tc.Unit), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestPatchesToUnit(t *testing.T) {
+	dmp := New()
+
+	text1 := "日本語 text, 🦊 and more 日本語 text."
+	text2 := "日本 text, 🐶 and more 日本語 texts."
+
+	patches := dmp.PatchMake(text1, text2)
+	assert.Equal(t, "@@ -1,24 +1,21 @@\n %E6%97%A5%E6%9C%AC\n-%E8%AA%9E\n  text, \n-%F0%9F%A6%8A\n+%F0%9F%90%B6\n  and\n@@ -34,9 +34,10 @@\n %E8%AA%9E text\n+s\n .\n", dmp.PatchToText(patches))
+
+	runePatches := dmp.patchesToUnit(dmp.PatchDeepCopy(patches), text1, UnitByte, UnitRune)
+	assert.Equal(t, "@@ -1,15 +1,14 @@\n %E6%97%A5%E6%9C%AC\n-%E8%AA%9E\n  text, \n-%F0%9F%A6%8A\n+%F0%9F%90%B6\n  and\n@@ -23,7 +23,8 @@\n %E8%AA%9E text\n+s\n .\n", dmp.PatchToText(runePatches))
+
+	dmp.PatchUnit = UnitRune
+	assert.Equal(t, runePatches, dmp.PatchMake(text1, text2))
+	assert.Equal(t, patches, dmp.patchesToUnit(runePatches, text1, UnitRune, UnitByte))
+
+	// The context of the second patch starts within the text the first one inserted.
+	dmp.PatchUnit = UnitByte
+	text1 = "\u65e5c\u00e9\U0001f436b\U0001f436"
+	text2 = "\u65e5\U0001f436\U0001f436\u00e9\U0001f436b\U0001f436b "
+
+	patches = dmp.PatchMake(text1, text2)
+	assert.Equal(t, "@@ -1,10 +1,17 @@\n %E6%97%A5\n-c\n+%F0%9F%90%B6%F0%9F%90%B6\n %C3%A9%F0%9F%90%B6\n@@ -8,15 +8,17 @@\n %F0%9F%90%B6%C3%A9%F0%9F%90%B6b%F0%9F%90%B6\n+b \n", dmp.PatchToText(patches))
+
+	runePatches = dmp.patchesToUnit(dmp.PatchDeepCopy(patches), text1, UnitByte, UnitRune)
+	assert.Equal(t, "@@ -1,4 +1,5 @@\n %E6%97%A5\n-c\n+%F0%9F%90%B6%F0%9F%90%B6\n %C3%A9%F0%9F%90%B6\n@@ -3,5 +3,7 @@\n %F0%9F%90%B6%C3%A9%F0%9F%90%B6b%F0%9F%90%B6\n+b \n", dmp.PatchToText(runePatches))
+	assert.Equal(t, patches, dmp.patchesToUnit(runePatches, text1, UnitRune, UnitByte))
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// wordClass is the word break property of a rune as far as TokenizeWords distinguishes them.
+type wordClass int8
+
+const (
+	wordOther wordClass = iota
+	wordCR
+	wordLF
+	wordNewline
+	wordSpace
+	wordExtend
+	wordLetter
+	wordNumeric
+	wordKatakana
+	wordExtendNumLet
+	wordMidLetter
+	wordMidNum
+	wordMidNumLet
+	wordSingleQuote
+	wordRegionalIndicator
+)
+
+// wordClassOf returns the word break property of a rune.
+func wordClassOf(r rune) wordClass {
+	switch r {
+	case '\r':
+		return wordCR
+	case '\n':
+		return wordLF
+	case '\v', '\f', '\u0085', '\u2028', '\u2029':
+		return wordNewline
+	case '\'':
+		return wordSingleQuote
+	case ':', '\u00b7', '\u0387', '\u055f', '\u05f4', '\u2027', '\ufe13', '\ufe55', '\uff1a':
+		return wordMidLetter
+	case ',', ';', '\u037e', '\u0589', '\u060c', '\u060d', '\u066c', '\u07f8', '\u2044', '\ufe10', '\ufe14', '\ufe50', '\ufe54', '\uff0c', '\uff1b':
+		return wordMidNum
+	case '.', '\u2018', '\u2019', '\u2024', '\ufe52', '\uff07', '\uff0e':
+		return wordMidNumLet
+	}
+
+	if r < utf8.RuneSelf {
+		// ASCII (speedup).
+		switch {
+		case 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z':
+			return wordLetter
+		case '0' <= r && r <= '9':
+			return wordNumeric
+		case r == '_':
+			return wordExtendNumLet
+		case r == ' ' || r == '\t':
+			return wordSpace
+		}
+		return wordOther
+	}
+
+	switch {
+	case r >= '\U0001f1e6' && r <= '\U0001f1ff':
+		return wordRegionalIndicator
+	case unicode.IsSpace(r):
+		return wordSpace
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc, unicode.Cf):
+		return wordExtend
+	case unicode.In(r, unicode.Katakana) || r == '\u30fc' || r == '\u309b' || r == '\u309c':
+		return wordKatakana
+	case unicode.In(r, unicode.Han, unicode.Hiragana):
+		// Ideographs and Hiragana are words on their own.
+		return wordOther
+	case unicode.IsLetter(r):
+		return wordLetter
+	case unicode.In(r, unicode.Nd):
+		return wordNumeric
+	case unicode.In(r, unicode.Pc):
+		return wordExtendNumLet
+	}
+
+	return wordOther
+}
+
+// isWordNewline returns true if the class is a line break.
+func isWordNewline(c wordClass) bool {
+	return c == wordCR || c == wordLF || c == wordNewline
+}
+
+// TokenizeWords splits a text at the word boundaries of Unicode Standard Annex #29.
+// Letters and digits, including those joined by apostrophes or decimal separators such as in "can't" and "3.14", form one token, as does every run of horizontal whitespace. Every other rune, such as a punctuation mark or an ideograph, is a token on its own, and combining marks stay with the rune before them.
+// The tokens add up to the text, invalid UTF-8 sequences are tokens of their own.
+func TokenizeWords(text string) []string {
+	// Units are runes along with the combining marks which follow them.
+	var starts []int
+	var classes []wordClass
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		class := wordClassOf(r)
+		if r == utf8.RuneError && size == 1 {
+			class = wordOther
+		}
+		i += size
+
+		if n := len(classes); class == wordExtend && n > 0 && !isWordNewline(classes[n-1]) {
+			// Combining marks do not change the class of the unit they belong to.
+			continue
+		}
+		starts = append(starts, i-size)
+		classes = append(classes, class)
+	}
+
+	classAt := func(i int) wordClass {
+		if i < 0 || i >= len(classes) {
+			return wordOther
+		}
+		return classes[i]
+	}
+
+	isAlphaNumeric := func(c wordClass) bool {
+		return c == wordLetter || c == wordNumeric
+	}
+	isMidLetter := func(c wordClass) bool {
+		return c == wordMidLetter || c == wordMidNumLet || c == wordSingleQuote
+	}
+	isMidNum := func(c wordClass) bool {
+		return c == wordMidNum || c == wordMidNumLet || c == wordSingleQuote
+	}
+	isExtendNumLet := func(c wordClass) bool {
+		return isAlphaNumeric(c) || c == wordKatakana || c == wordExtendNumLet
+	}
+
+	var tokens []string
+	start := 0
+	regionalIndicators := 0
+	for i := 1; i < len(classes); i++ {
+		before2, before, after, after2 := classAt(i-2), classes[i-1], classes[i], classAt(i+1)
+		if before == wordRegionalIndicator {
+			regionalIndicators++
+		} else {
+			regionalIndicators = 0
+		}
+
+		join := false
+		switch {
+		case before == wordCR && after == wordLF:
+			join = true
+		case isWordNewline(before) || isWordNewline(after):
+			join = false
+		case before == wordSpace && after == wordSpace:
+			join = true
+		case isAlphaNumeric(before) && isAlphaNumeric(after):
+			join = true
+		case before == wordLetter && isMidLetter(after) && after2 == wordLetter,
+			before2 == wordLetter && isMidLetter(before) && after == wordLetter:
+			join = true
+		case before == wordNumeric && isMidNum(after) && after2 == wordNumeric,
+			before2 == wordNumeric && isMidNum(before) && after == wordNumeric:
+			join = true
+		case before == wordKatakana && after == wordKatakana:
+			join = true
+		case isExtendNumLet(before) && after == wordExtendNumLet,
+			before == wordExtendNumLet && isExtendNumLet(after):
+			join = true
+		case before == wordRegionalIndicator && after == wordRegionalIndicator:
+			// Flags are pairs of regional indicators.
+			join = regionalIndicators%2 == 1
+		}
+
+		if !join {
+			tokens = append(tokens, text[start:starts[i]])
+			start = starts[i]
+		}
+	}
+	if len(text) > 0 {
+		tokens = append(tokens, text[start:])
+	}
+
+	return tokens
+}
+
+// DiffMainWords finds the differences between two texts word by word.
+// The texts are split into tokens by DiffTokenizer, and every diff covers whole tokens. This is better suited for prose than a character based diff and has no limit on the number of distinct tokens.
+func (dmp *DiffMatchPatch) DiffMainWords(text1, text2 string) []Diff {
+	tokenize := dmp.DiffTokenizer
+	if tokenize == nil {
+		tokenize = TokenizeWords
+	}
+	tokens1 := tokenize(text1)
+	tokens2 := tokenize(text2)
+
+	var diffs []Diff
+	for _, edit := range DiffSequence(dmp, tokens1, tokens2) {
+		if edit.Type == DiffInsert {
+			diffs = append(diffs, Diff{edit.Type, strings.Join(tokens2[edit.Start2:edit.End2], "")})
+		} else {
+			diffs = append(diffs, Diff{edit.Type, strings.Join(tokens1[edit.Start1:edit.End1], "")})
+		}
+	}
+
+	return diffs
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenizeWords(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text string
+
+		Expected []string
+	}
+
+	for i, tc := range []TestCase{
+		{"Null case", "", nil},
+		{"Words", "Hello, world!", []string{"Hello", ",", " ", "world", "!"}},
+		{"Whitespace runs", "a  \tb", []string{"a", "  \t", "b"}},
+		{"Line breaks", "a\r\n\n b", []string{"a", "\r\n", "\n", " ", "b"}},
+		{"Apostrophes", "can't 'quoted'", []string{"can't", " ", "'", "quoted", "'"}},
+		{"Abbreviations", "e.g. U.S.A.", []string{"e.g", ".", " ", "U.S.A", "."}},
+		{"Numbers", "3.14 1,000,000 v2.0", []string{"3.14", " ", "1,000,000", " ", "v2.0"}},
+		{"Underscores", "snake_case _x", []string{"snake_case", " ", "_x"}},
+		{"Combining marks", "café ́", []string{"café", " ́"}},
+		{"Ideographs", "中文字", []string{"中", "文", "字"}},
+		{"Hiragana and Katakana", "ひらがなカタカナー", []string{"ひ", "ら", "が", "な", "カタカナー"}},
+		{"Cyrillic and Greek", "Привет κόσμε", []string{"Привет", " ", "κόσμε"}},
+		{"Regional indicators", "\U0001f1e9\U0001f1ea\U0001f1eb\U0001f1f7\U0001f1ee", []string{"\U0001f1e9\U0001f1ea", "\U0001f1eb\U0001f1f7", "\U0001f1ee"}},
+		{"Invalid UTF-8", "ab\xffcd", []string{"ab", "\xff", "cd"}},
+	} {
+		actual := TokenizeWords(tc.Text)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Text, strings.Join(actual, ""), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestDiffMainWords(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+
+		Expected []Diff
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Null case", "", "", nil},
+		{"Equality", "The quick fox.", "The quick fox.", []Diff{{DiffEqual, "The quick fox."}}},
+		{"Replaced word", "The quick brown fox", "The quick bread fox", []Diff{{DiffEqual, "The quick "}, {DiffDelete, "brown"}, {DiffInsert, "bread"}, {DiffEqual, " fox"}}},
+		{"Inserted words", "The fox jumps.", "The red fox jumps high.", []Diff{{DiffEqual, "The "}, {DiffInsert, "red "}, {DiffEqual, "fox jumps"}, {DiffInsert, " high"}, {DiffEqual, "."}}},
+		{"Deleted punctuation", "Hello, world!", "Hello world", []Diff{{DiffEqual, "Hello"}, {DiffDelete, ","}, {DiffEqual, " world"}, {DiffDelete, "!"}}},
+		{"Ideographs", "我爱中文", "我学中文", []Diff{{DiffEqual, "我"}, {DiffDelete, "爱"}, {DiffInsert, "学"}, {DiffEqual, "中文"}}},
+	} {
+		actual := dmp.DiffMainWords(tc.Text1, tc.Text2)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Custom tokenizer.
+	dmp.DiffTokenizer = func(text string) []string {
+		return strings.SplitAfter(text, ",")
+	}
+	assert.Equal(t, []Diff{{DiffEqual, "a b,"}, {DiffDelete, "c d,"}, {DiffInsert, "c e,"}, {DiffEqual, "f"}}, dmp.DiffMainWords("a b,c d,f", "a b,c e,f"))
+	dmp.DiffTokenizer = nil
+
+	// Many distinct words.
+	var words1, words2 []string
+	for i := 0; i < 100000; i++ {
+		words1 = append(words1, "w"+strconv.Itoa(i))
+		if i == 50000 {
+			words2 = append(words2, "x")
+		} else {
+			words2 = append(words2, "w"+strconv.Itoa(i))
+		}
+	}
+	text1 := strings.Join(words1, " ")
+	text2 := strings.Join(words2, " ")
+
+	diffs := dmp.DiffMainWords(text1, text2)
+	assert.Equal(t, text1, dmp.DiffText1(diffs))
+	assert.Equal(t, text2, dmp.DiffText2(diffs))
+	assert.Equal(t, []Diff{{DiffDelete, "w50000"}, {DiffInsert, "x"}}, diffs[1:3])
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+// Package diffsync implements Differential Synchronization, which keeps copies of a text in sync while all of them are being edited.
+//
+// The server keeps a shadow of the text for every client, and every client keeps a shadow of the text too. Both shadows are what the two sides agreed on when they last synchronized. A sync cycle diffs the text against the shadow, sends the changes as deltas to the other side, and patches them into the text over there. The client sends its changes to the server, which responds with its own changes.
+//
+// Edits stay on an edit stack until the other side acknowledges them, and a backup of the shadow allows starting over if the other side missed the latest edits. So no edit gets lost if messages do.
+//
+// See https://neil.fraser.name/writing/sync/ for a description of the protocol.
+package diffsync
+
+import (
+	"errors"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// ErrOutOfSync is returned if a message does not fit the shadow, e.g. because it was made up or its side was restarted. The client has to join the server again.
+var ErrOutOfSync = errors.New("Shadows are out of sync")
+
+// Edit is a change of the shadow which was sent to the other side.
+type Edit struct {
+	// Version is the local version of the shadow the edit was made to.
+	Version int
+	// Delta describes the change of the shadow as created by DiffToDelta.
+	Delta string
+}
+
+// Message carries edits from one side to the other.
+type Message struct {
+	// Version is the number of edits the sender received from the receiver so far, which acknowledges them.
+	Version int
+	// Edits holds the edits the receiver did not acknowledge yet.
+	Edits []Edit
+}
+
+// Session is the state of one side of a synchronization, apart from the text itself.
+type Session struct {
+	// Shadow is the text which both sides agreed on when they last synchronized, along with the edits sent since then.
+	Shadow string
+	// LocalVersion is the number of edits this side made to the shadow.
+	LocalVersion int
+	// RemoteVersion is the number of edits this side received from the other side.
+	RemoteVersion int
+	// Backup is the shadow as it was before this side sent its latest edits, which the other side might have missed.
+	Backup string
+	// BackupVersion is the local version of the backup.
+	BackupVersion int
+	// Edits is the edit stack holding the edits which the other side did not acknowledge yet.
+	Edits []Edit
+}
+
+// newSession returns the state of a side which starts synchronizing text.
+func newSession(text string) Session {
+	return Session{
+		Shadow: text,
+		Backup: text,
+	}
+}
+
+// send diffs text against the shadow and returns a message with the new edit and all edits before it which are not acknowledged yet.
+func (s *Session) send(dmp *diffmatchpatch.DiffMatchPatch, text string) Message {
+	if text != s.Shadow {
+		diffs := dmp.DiffMain(s.Shadow, text, true)
+		diffs = dmp.DiffCleanupEfficiency(diffs)
+		s.Edits = append(s.Edits, Edit{
+			Version: s.LocalVersion,
+			Delta:   dmp.DiffToDelta(diffs),
+		})
+		s.LocalVersion++
+		s.Shadow = text
+	}
+
+	return Message{
+		Version: s.RemoteVersion,
+		Edits:   append([]Edit(nil), s.Edits...),
+	}
+}
+
+// receive applies the edits of a message to the shadow and patches them into text, which is returned.
+// Edits which were received before are skipped.
+func (s *Session) receive(dmp *diffmatchpatch.DiffMatchPatch, text string, m Message) (string, error) {
+	if m.Version != s.LocalVersion {
+		if m.Version != s.BackupVersion {
+			return text, ErrOutOfSync
+		}
+		// The other side missed the latest edits and made its own to the backup. Start over from there, the next message sends the missed edits again.
+		s.Shadow = s.Backup
+		s.LocalVersion = s.BackupVersion
+	}
+	// The other side acknowledged every edit up to the local version, any later ones are part of the next diff.
+	s.Edits = nil
+
+	for _, edit := range m.Edits {
+		if edit.Version < s.RemoteVersion {
+			// The acknowledgement of this edit got lost.
+			continue
+		}
+		if edit.Version > s.RemoteVersion {
+			return text, ErrOutOfSync
+		}
+
+		diffs, err := dmp.DiffFromDelta(s.Shadow, edit.Delta)
+		if err != nil {
+			return text, err
+		}
+		text, _ = dmp.PatchApply(dmp.PatchMake(s.Shadow, diffs), text)
+		s.Shadow = dmp.DiffText2(diffs)
+		s.RemoteVersion++
+	}
+
+	// Both sides agree on the shadow now.
+	s.Backup = s.Shadow
+	s.BackupVersion = s.LocalVersion
+
+	return text, nil
+}
+
+// Client is a text which is synchronized with a server.
+// A client is not safe for concurrent use.
+type Client struct {
+	Session
+
+	// ID identifies the client at the server.
+	ID string
+	// Text is the text of the client, which can be edited between sync cycles.
+	Text string
+
+	// DiffMatchPatch diffs and patches the text.
+	DiffMatchPatch *diffmatchpatch.DiffMatchPatch
+}
+
+// NewClient returns a client which starts synchronizing text, which has to be the text of the server at the time the client joined.
+func NewClient(id, text string) *Client {
+	return &Client{
+		Session:        newSession(text),
+		ID:             id,
+		Text:           text,
+		DiffMatchPatch: diffmatchpatch.New(),
+	}
+}
+
+// Send starts a sync cycle and returns the message for the server.
+func (c *Client) Send() Message {
+	return c.send(c.DiffMatchPatch, c.Text)
+}
+
+// Receive completes a sync cycle with the response of the server, patching its edits into the text.
+func (c *Client) Receive(m Message) error {
+	text, err := c.receive(c.DiffMatchPatch, c.Text, m)
+	c.Text = text
+	return err
+}
+
+// Sync runs a sync cycle over a transport.
+// If a message gets lost, nothing is lost but the cycle, the next one catches up.
+func (c *Client) Sync(transport Transport) error {
+	response, err := transport.RoundTrip(c.ID, c.Send())
+	if err != nil {
+		return err
+	}
+	return c.Receive(response)
+}
+
+// Server is a text which is synchronized with any number of clients.
+// A server is not safe for concurrent use.
+type Server struct {
+	// Text is the text of the server, which can be edited between sync cycles.
+	Text string
+	// Sessions holds the state of the synchronization with every client by its ID.
+	Sessions map[string]*Session
+
+	// DiffMatchPatch diffs and patches the text.
+	DiffMatchPatch *diffmatchpatch.DiffMatchPatch
+}
+
+// NewServer returns a server which synchronizes text.
+func NewServer(text string) *Server {
+	return &Server{
+		Text:           text,
+		Sessions:       map[string]*Session{},
+		DiffMatchPatch: diffmatchpatch.New(),
+	}
+}
+
+// Join starts synchronizing the text with a client and returns the client.
+// A client which joins again starts over.
+func (s *Server) Join(id string) *Client {
+	session := newSession(s.Text)
+	s.Sessions[id] = &session
+	return NewClient(id, s.Text)
+}
+
+// Leave stops synchronizing the text with a client.
+func (s *Server) Leave(id string) {
+	delete(s.Sessions, id)
+}
+
+// Receive handles the message of a client, patching its edits into the text, and returns the response.
+func (s *Server) Receive(id string, m Message) (Message, error) {
+	session, ok := s.Sessions[id]
+	if !ok {
+		return Message{}, errors.New("Unknown client: " + id)
+	}
+
+	text, err := session.receive(s.DiffMatchPatch, s.Text, m)
+	s.Text = text
+	if err != nil {
+		return Message{}, err
+	}
+
+	return session.send(s.DiffMatchPatch, s.Text), nil
+}
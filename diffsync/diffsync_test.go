@@ -0,0 +1,144 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffsync
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSync(t *testing.T) {
+	server := NewServer("The quick brown fox jumps over the lazy dog.")
+	transport := &MemoryTransport{Server: server}
+	alice := server.Join("alice")
+	bob := server.Join("bob")
+
+	// Edits of one client reach the server and the other client.
+	alice.Text = "The quick red fox jumps over the lazy dog."
+	assert.NoError(t, alice.Sync(transport))
+	assert.Equal(t, "The quick red fox jumps over the lazy dog.", server.Text)
+	assert.NoError(t, bob.Sync(transport))
+	assert.Equal(t, "The quick red fox jumps over the lazy dog.", bob.Text)
+
+	// Concurrent edits of the clients and the server are merged.
+	alice.Text = "A quick red fox jumps over the lazy dog."
+	bob.Text = "The quick red fox jumps over the lazy cat."
+	server.Text = "The quick red fox leaps over the lazy dog."
+	for _, client := range []*Client{alice, bob, alice} {
+		assert.NoError(t, client.Sync(transport))
+	}
+	expected := "A quick red fox leaps over the lazy cat."
+	assert.Equal(t, expected, server.Text)
+	assert.Equal(t, expected, alice.Text)
+	assert.Equal(t, expected, bob.Text)
+
+	// Another cycle acknowledges the last edits.
+	for _, client := range []*Client{bob, alice} {
+		assert.NoError(t, client.Sync(transport))
+	}
+	for _, session := range []*Session{&alice.Session, &bob.Session, server.Sessions["alice"], server.Sessions["bob"]} {
+		assert.Empty(t, session.Edits)
+		assert.Equal(t, expected, session.Shadow)
+		assert.Equal(t, expected, session.Backup)
+	}
+
+	// A client which left is unknown.
+	server.Leave("bob")
+	bob.Text = "Bob was here."
+	assert.Error(t, bob.Sync(transport))
+	assert.Equal(t, expected, server.Text)
+}
+
+func TestSyncErrors(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Message Message
+	}
+
+	for i, tc := range []TestCase{
+		{"Unknown version", Message{Version: 3}},
+		{"Missing edit", Message{Edits: []Edit{{Version: 1, Delta: "=5\t+!"}}}},
+		{"Invalid delta", Message{Edits: []Edit{{Version: 0, Delta: "=6\t+!"}}}},
+	} {
+		server := NewServer("Hello")
+		server.Join("alice")
+
+		_, err := server.Receive("alice", tc.Message)
+		assert.Error(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, "Hello", server.Text, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	server := NewServer("Hello")
+	_, err := server.Receive("alice", Message{})
+	assert.Error(t, err)
+
+	// A client which is out of sync joins again to start over.
+	alice := server.Join("alice")
+	alice.RemoteVersion = 1
+	alice.Text = "Hello world"
+	assert.Equal(t, ErrOutOfSync, alice.Sync(&MemoryTransport{Server: server}))
+	alice = server.Join("alice")
+	alice.Text = "Hello world"
+	assert.NoError(t, alice.Sync(&MemoryTransport{Server: server}))
+	assert.Equal(t, "Hello world", server.Text)
+}
+
+func TestSyncLostMessages(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	server := NewServer("")
+	transport := &MemoryTransport{
+		Server: server,
+		LoseRequest: func(id string, m Message) bool {
+			return random.Intn(3) == 0
+		},
+		LoseResponse: func(id string, m Message) bool {
+			return random.Intn(3) == 0
+		},
+	}
+	clients := []*Client{server.Join("alice"), server.Join("bob"), server.Join("carol")}
+
+	// Every side appends lines now and then, no matter how many messages are lost.
+	var lines []string
+	for i := 0; i < 100; i++ {
+		line := fmt.Sprintf("Line %d\n", i)
+		lines = append(lines, line)
+		switch n := random.Intn(len(clients) + 1); n {
+		case len(clients):
+			server.Text += line
+		default:
+			clients[n].Text += line
+		}
+
+		client := clients[random.Intn(len(clients))]
+		if err := client.Sync(transport); err != nil {
+			assert.Equal(t, ErrMessageLost, err, fmt.Sprintf("Cycle #%d", i))
+		}
+	}
+
+	// Once messages get through again, everybody ends up with every line.
+	transport.LoseRequest = nil
+	transport.LoseResponse = nil
+	for i := 0; i < 2; i++ {
+		for _, client := range clients {
+			assert.NoError(t, client.Sync(transport))
+		}
+	}
+	for _, line := range lines {
+		assert.Contains(t, server.Text, line)
+	}
+	assert.Equal(t, len(lines), strings.Count(server.Text, "\n"))
+	for _, client := range clients {
+		assert.Equal(t, server.Text, client.Text, client.ID)
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffsync
+
+import (
+	"errors"
+)
+
+// ErrMessageLost is returned by MemoryTransport if it loses a message.
+var ErrMessageLost = errors.New("Message lost")
+
+// Transport carries the messages of a client to the server and back.
+type Transport interface {
+	// RoundTrip delivers the message of a client to the server and returns the response.
+	RoundTrip(id string, m Message) (Message, error)
+}
+
+// MemoryTransport delivers messages to a server in the same process, e.g. for testing.
+// Messages are copied on their way just like on a network, and they can be lost in either direction.
+type MemoryTransport struct {
+	// Server receives the messages.
+	Server *Server
+
+	// LoseRequest decides if the message of a client is lost on its way to the server. If it is nil, no message is lost.
+	LoseRequest func(id string, m Message) bool
+	// LoseResponse decides if the response of the server is lost on its way to the client. If it is nil, no response is lost.
+	LoseResponse func(id string, m Message) bool
+}
+
+// RoundTrip delivers the message of a client to the server and returns the response.
+// Returns ErrMessageLost if the message or the response is lost.
+func (t *MemoryTransport) RoundTrip(id string, m Message) (Message, error) {
+	if t.LoseRequest != nil && t.LoseRequest(id, m) {
+		return Message{}, ErrMessageLost
+	}
+	response, err := t.Server.Receive(id, copyMessage(m))
+	if err != nil {
+		return Message{}, err
+	}
+	if t.LoseResponse != nil && t.LoseResponse(id, response) {
+		return Message{}, ErrMessageLost
+	}
+	return copyMessage(response), nil
+}
+
+// copyMessage returns a copy of a message which shares nothing with it.
+func copyMessage(m Message) Message {
+	m.Edits = append([]Edit(nil), m.Edits...)
+	return m
+}
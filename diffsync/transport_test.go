@@ -0,0 +1,59 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffsync
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryTransport(t *testing.T) {
+	server := NewServer("Hello")
+	alice := server.Join("alice")
+	lose := true
+	transport := &MemoryTransport{
+		Server: server,
+		LoseRequest: func(id string, m Message) bool {
+			return lose
+		},
+	}
+
+	// A lost request does not reach the server, but its edit stays on the edit stack.
+	alice.Text = "Hello world"
+	assert.Equal(t, ErrMessageLost, alice.Sync(transport))
+	assert.Equal(t, "Hello", server.Text)
+	assert.Len(t, alice.Edits, 1)
+
+	// A lost response does not reach the client.
+	lose = false
+	transport.LoseResponse = func(id string, m Message) bool {
+		return true
+	}
+	server.Text = "Oh, Hello"
+	assert.Equal(t, ErrMessageLost, alice.Sync(transport))
+	assert.Equal(t, "Oh, Hello world", server.Text)
+	assert.Equal(t, "Hello world", alice.Text)
+	assert.Len(t, alice.Edits, 1)
+
+	// Messages are copied on their way.
+	transport.LoseResponse = nil
+	m := alice.Send()
+	response, err := transport.RoundTrip("alice", m)
+	assert.NoError(t, err)
+	m.Edits[0].Delta = ""
+	assert.Equal(t, "=5\t+ world", alice.Edits[0].Delta)
+	delta := response.Edits[0].Delta
+	response.Edits[0].Delta = ""
+	assert.Equal(t, "+Oh, \t=11", server.Sessions["alice"].Edits[0].Delta)
+	response.Edits[0].Delta = delta
+
+	assert.NoError(t, alice.Receive(response))
+	assert.Equal(t, "Oh, Hello world", alice.Text)
+}
@@ -12,7 +12,7 @@ OUT=$(errcheck $PKG/...)
 if [ $(echo "$OUT\c" | wc -l) -ne 0 ]; then echo "$OUT"; PROBLEM=1; fi
 
 echo "go vet:"
-OUT=$(go tool vet -all=true -v=true $ROOT_DIR 2>&1 | grep --invert-match -E "(Checking file|\%p of wrong type|can't check non-constant format)")
+OUT=$(go vet $PKG/... 2>&1)
 if [ $(echo "$OUT\c" | wc -l) -ne 0 ]; then echo "$OUT"; PROBLEM=1; fi
 
 echo "golint:"